@@ -0,0 +1,134 @@
+package strdel
+
+import (
+	"sort"
+	"unicode/utf8"
+)
+
+// automaton is an Aho-Corasick automaton that finds all occurrences of a set
+// of words in a single pass. It works on runes, which are passed through
+// fold before they are compared.
+type automaton struct {
+	nodes  []acNode
+	fold   func(rune) rune
+	maxLen int // length of the longest word in runes
+}
+
+type acNode struct {
+	next   map[rune]int
+	fail   int // node of the longest proper suffix that is in the trie
+	dict   int // nearest node on the fail chain that ends a word, or -1
+	word   int // index of the word ending at this node, or -1
+	length int // depth of the node in runes
+}
+
+// span is the byte range of a match of word in a string.
+type span struct {
+	start, end int
+	word       int
+}
+
+func newAutomaton(words []string, fold func(rune) rune) *automaton {
+	a := &automaton{
+		nodes: []acNode{{dict: -1, word: -1}},
+		fold:  fold,
+	}
+
+	for i, w := range words {
+		n := 0
+		for _, r := range w {
+			r = fold(r)
+			child, ok := a.nodes[n].next[r]
+			if !ok {
+				child = len(a.nodes)
+				a.nodes = append(a.nodes, acNode{
+					dict:   -1,
+					word:   -1,
+					length: a.nodes[n].length + 1,
+				})
+				if a.nodes[n].next == nil {
+					a.nodes[n].next = map[rune]int{}
+				}
+				a.nodes[n].next[r] = child
+			}
+			n = child
+		}
+		if a.nodes[n].word < 0 {
+			a.nodes[n].word = i
+		}
+		if a.nodes[n].length > a.maxLen {
+			a.maxLen = a.nodes[n].length
+		}
+	}
+
+	// Link the nodes breadth first, so the fail node of every node is
+	// complete before its children are visited.
+	queue := []int{}
+	for _, child := range a.nodes[0].next {
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		for r, child := range a.nodes[n].next {
+			fail := a.step(a.nodes[n].fail, r)
+			a.nodes[child].fail = fail
+			if a.nodes[fail].word >= 0 {
+				a.nodes[child].dict = fail
+			} else {
+				a.nodes[child].dict = a.nodes[fail].dict
+			}
+			queue = append(queue, child)
+		}
+	}
+	return a
+}
+
+// step returns the node reached from node n on rune r.
+func (a *automaton) step(n int, r rune) int {
+	for {
+		if next, ok := a.nodes[n].next[r]; ok {
+			return next
+		}
+		if n == 0 {
+			return 0
+		}
+		n = a.nodes[n].fail
+	}
+}
+
+// find returns all, possibly overlapping, occurrences of the words in s
+// ordered by start and, for equal starts, longest first.
+func (a *automaton) find(s string) []span {
+	var found []span
+
+	// starts holds the byte offsets of the last maxLen+1 runes.
+	starts := make([]int, a.maxLen+1)
+	n := 0
+	for i, pos := 0, 0; pos < len(s); i++ {
+		r, width := utf8.DecodeRuneInString(s[pos:])
+		starts[i%len(starts)] = pos
+		pos += width
+
+		n = a.step(n, a.fold(r))
+		m := n
+		if a.nodes[m].word < 0 {
+			m = a.nodes[m].dict
+		}
+		for ; m > 0; m = a.nodes[m].dict {
+			found = append(found, span{
+				start: starts[(i+1-a.nodes[m].length)%len(starts)],
+				end:   pos,
+				word:  a.nodes[m].word,
+			})
+		}
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		if found[i].start != found[j].start {
+			return found[i].start < found[j].start
+		}
+		return found[i].end > found[j].end
+	})
+	return found
+}
@@ -0,0 +1,23 @@
+package strdel
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_automaton_haveOverlappingWords_AllOccurrencesAreFound(t *testing.T) {
+	words := []string{`he`, `she`, `his`, `hers`, `ß`}
+	a := newAutomaton(words, func(r rune) rune { return r })
+
+	got := a.find(`ushers ßhe`)
+	want := []span{
+		{start: 1, end: 4, word: 1},  // she
+		{start: 2, end: 6, word: 3},  // hers
+		{start: 2, end: 4, word: 0},  // he
+		{start: 7, end: 9, word: 4},  // ß
+		{start: 9, end: 11, word: 0}, // he
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("call find\n\texp: %+v\n\n\tgot: %+v", want, got)
+	}
+}
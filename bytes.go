@@ -0,0 +1,304 @@
+package strdel
+
+import (
+	"strings"
+	"unsafe"
+)
+
+// The Bytes variants of the strdel functions work on byte slices without
+// converting them to strings. If nothing is deleted they return b itself,
+// and if the result is part of b, like text without its trailing line
+// breaks, they return a slice of b; in both cases without allocating.
+//
+// The regular expression, space and word functions do not allocate at all
+// if nothing matches, unless a word is itself a regular expression. The
+// LaTeX functions only avoid allocations for text without the brackets they
+// work on.
+
+// view returns b as a string without copying it. The string must not be
+// used after b is modified.
+func view(b []byte) string {
+	return unsafe.String(unsafe.SliceData(b), len(b))
+}
+
+// result returns out, computed from view(b), as a byte slice. It returns
+// the part of b that out shares memory with, or a copy of out otherwise.
+func result(b []byte, out string) []byte {
+	if out == "" {
+		return b[:0:0]
+	}
+	start := uintptr(unsafe.Pointer(unsafe.SliceData(b)))
+	p := uintptr(unsafe.Pointer(unsafe.StringData(out)))
+	if start <= p && p+uintptr(len(out)) <= start+uintptr(len(b)) {
+		i := int(p - start)
+		return b[i : i+len(out) : i+len(out)]
+	}
+	return []byte(out)
+}
+
+func apply(b []byte, fn func(string) string) []byte {
+	return result(b, fn(view(b)))
+}
+
+func applyE(b []byte, fn func(string) (string, error)) ([]byte, error) {
+	s, err := fn(view(b))
+	return result(b, s), err
+}
+
+// applyReport is applyE for the report functions. The text of the
+// deletions is copied, so it stays valid when b changes.
+func applyReport(b []byte, fn func(string) (string, []Deletion, error)) ([]byte, []Deletion, error) {
+	s, deletions, err := fn(view(b))
+	for i := range deletions {
+		deletions[i].Text = strings.Clone(deletions[i].Text)
+	}
+	return result(b, s), deletions, err
+}
+
+// WordBytes is like Word but works on a byte slice.
+func WordBytes(b []byte, wordToDelete string) []byte {
+	return apply(b, func(s string) string { return Word(s, wordToDelete) })
+}
+
+// WordEBytes is like WordE but works on a byte slice.
+func WordEBytes(b []byte, wordToDelete string) ([]byte, error) {
+	return applyE(b, func(s string) (string, error) { return WordE(s, wordToDelete) })
+}
+
+// WordWithOptionsBytes is like WordWithOptions but works on a byte slice.
+func WordWithOptionsBytes(b []byte, wordToDelete string, opts Options) ([]byte, error) {
+	return applyE(b, func(s string) (string, error) {
+		return WordWithOptions(s, wordToDelete, opts)
+	})
+}
+
+// WordsBytes is like Words but works on a byte slice.
+func WordsBytes(b []byte, words []string, opts Options) ([]byte, error) {
+	return applyE(b, func(s string) (string, error) { return Words(s, words, opts) })
+}
+
+// WordReportBytes is like WordReport but works on a byte slice.
+func WordReportBytes(b []byte, wordToDelete string, opts Options) ([]byte, []Deletion, error) {
+	return applyReport(b, func(s string) (string, []Deletion, error) {
+		return WordReport(s, wordToDelete, opts)
+	})
+}
+
+// WordsReportBytes is like WordsReport but works on a byte slice.
+func WordsReportBytes(b []byte, words []string, opts Options) ([]byte, []Deletion, error) {
+	return applyReport(b, func(s string) (string, []Deletion, error) {
+		return WordsReport(s, words, opts)
+	})
+}
+
+// RegExpBytes is like RegExp but works on a byte slice.
+func RegExpBytes(b []byte, regExp string) []byte {
+	return apply(b, func(s string) string { return RegExp(s, regExp) })
+}
+
+// RegExpEBytes is like RegExpE but works on a byte slice.
+func RegExpEBytes(b []byte, regExp string) ([]byte, error) {
+	return applyE(b, func(s string) (string, error) { return RegExpE(s, regExp) })
+}
+
+// RegExpWithOptionsBytes is like RegExpWithOptions but works on a byte
+// slice.
+func RegExpWithOptionsBytes(b []byte, regExp string, opts Options) ([]byte, error) {
+	return applyE(b, func(s string) (string, error) {
+		return RegExpWithOptions(s, regExp, opts)
+	})
+}
+
+// RegExpReportBytes is like RegExpReport but works on a byte slice.
+func RegExpReportBytes(b []byte, regExp string, opts Options) ([]byte, []Deletion, error) {
+	return applyReport(b, func(s string) (string, []Deletion, error) {
+		return RegExpReport(s, regExp, opts)
+	})
+}
+
+// NumberingBytes is like Numbering but works on a byte slice.
+func NumberingBytes(b []byte) []byte {
+	return apply(b, Numbering)
+}
+
+// NumberingWithOptionsBytes is like NumberingWithOptions but works on a
+// byte slice.
+func NumberingWithOptionsBytes(b []byte, opts NumberingOptions) []byte {
+	return apply(b, func(s string) string { return NumberingWithOptions(s, opts) })
+}
+
+// TrailingSpacesBytes is like TrailingSpaces but works on a byte slice.
+func TrailingSpacesBytes(b []byte) []byte {
+	return apply(b, TrailingSpaces)
+}
+
+// LeadingSpacesBytes is like LeadingSpaces but works on a byte slice.
+func LeadingSpacesBytes(b []byte) []byte {
+	return apply(b, LeadingSpaces)
+}
+
+// TrailingSpacesWithOptionsBytes is like TrailingSpacesWithOptions but
+// works on a byte slice.
+func TrailingSpacesWithOptionsBytes(b []byte, opts SpaceOptions) []byte {
+	return apply(b, func(s string) string { return TrailingSpacesWithOptions(s, opts) })
+}
+
+// LeadingSpacesWithOptionsBytes is like LeadingSpacesWithOptions but works
+// on a byte slice.
+func LeadingSpacesWithOptionsBytes(b []byte, opts SpaceOptions) []byte {
+	return apply(b, func(s string) string { return LeadingSpacesWithOptions(s, opts) })
+}
+
+// InternalSpacesBytes is like InternalSpaces but works on a byte slice.
+func InternalSpacesBytes(b []byte) []byte {
+	return apply(b, InternalSpaces)
+}
+
+// InternalSpacesWithOptionsBytes is like InternalSpacesWithOptions but
+// works on a byte slice.
+func InternalSpacesWithOptionsBytes(b []byte, opts InternalSpacesOptions) ([]byte, error) {
+	return applyE(b, func(s string) (string, error) {
+		return InternalSpacesWithOptions(s, opts)
+	})
+}
+
+// NormalizeSpacesBytes is like NormalizeSpaces but works on a byte slice.
+func NormalizeSpacesBytes(b []byte) []byte {
+	return apply(b, NormalizeSpaces)
+}
+
+// DedentBytes is like Dedent but works on a byte slice.
+func DedentBytes(b []byte) []byte {
+	return apply(b, Dedent)
+}
+
+// DedentWithOptionsBytes is like DedentWithOptions but works on a byte
+// slice.
+func DedentWithOptionsBytes(b []byte, opts DedentOptions) []byte {
+	return apply(b, func(s string) string { return DedentWithOptions(s, opts) })
+}
+
+// EmptyBracketsBytes is like EmptyBrackets but works on a byte slice.
+func EmptyBracketsBytes(b []byte) []byte {
+	return apply(b, EmptyBrackets)
+}
+
+// EmptyLinesInMacrosBytes is like EmptyLinesInMacros but works on a byte
+// slice.
+func EmptyLinesInMacrosBytes(b []byte) []byte {
+	return apply(b, EmptyLinesInMacros)
+}
+
+// EmptyMacrosBytes is like EmptyMacros but works on a byte slice.
+func EmptyMacrosBytes(b []byte, nestingDepth int) []byte {
+	return apply(b, func(s string) string { return EmptyMacros(s, nestingDepth) })
+}
+
+// EmptyMacrosUntilStableBytes is like EmptyMacrosUntilStable but works on a
+// byte slice.
+func EmptyMacrosUntilStableBytes(b []byte, maxPasses int) ([]byte, error) {
+	return applyE(b, func(s string) (string, error) {
+		return EmptyMacrosUntilStable(s, maxPasses)
+	})
+}
+
+// EmptyNestedMacrosBytes is like EmptyNestedMacros but works on a byte
+// slice.
+func EmptyNestedMacrosBytes(b []byte) []byte {
+	return apply(b, EmptyNestedMacros)
+}
+
+// SpaceBeforeClosingBracketsBytes is like SpaceBeforeClosingBrackets but
+// works on a byte slice.
+func SpaceBeforeClosingBracketsBytes(b []byte) []byte {
+	return apply(b, SpaceBeforeClosingBrackets)
+}
+
+// EmptyLineBytes is like EmptyLine but works on a byte slice.
+func EmptyLineBytes(b []byte) []byte {
+	return apply(b, EmptyLine)
+}
+
+// CollapseEmptyLinesBytes is like CollapseEmptyLines but works on a byte
+// slice.
+func CollapseEmptyLinesBytes(b []byte, max int) []byte {
+	return apply(b, func(s string) string { return CollapseEmptyLines(s, max) })
+}
+
+// CollapseEmptyLinesWithOptionsBytes is like CollapseEmptyLinesWithOptions
+// but works on a byte slice.
+func CollapseEmptyLinesWithOptionsBytes(b []byte, max int, opts CollapseOptions) []byte {
+	return apply(b, func(s string) string { return CollapseEmptyLinesWithOptions(s, max, opts) })
+}
+
+// SpaceAfterOpeningBracketsBytes is like SpaceAfterOpeningBrackets but
+// works on a byte slice.
+func SpaceAfterOpeningBracketsBytes(b []byte) []byte {
+	return apply(b, SpaceAfterOpeningBrackets)
+}
+
+// DuplicateLinesBytes is like DuplicateLines but works on a byte slice.
+func DuplicateLinesBytes(b []byte) []byte {
+	return apply(b, DuplicateLines)
+}
+
+// DuplicateLinesWithOptionsBytes is like DuplicateLinesWithOptions but
+// works on a byte slice.
+func DuplicateLinesWithOptionsBytes(b []byte, opts DedupeOptions) ([]byte, error) {
+	return applyE(b, func(s string) (string, error) {
+		return DuplicateLinesWithOptions(s, opts)
+	})
+}
+
+// DuplicateParagraphsBytes is like DuplicateParagraphs but works on a byte
+// slice.
+func DuplicateParagraphsBytes(b []byte) []byte {
+	return apply(b, DuplicateParagraphs)
+}
+
+// DuplicateParagraphsWithOptionsBytes is like
+// DuplicateParagraphsWithOptions but works on a byte slice.
+func DuplicateParagraphsWithOptionsBytes(b []byte, opts DedupeOptions) ([]byte, error) {
+	return applyE(b, func(s string) (string, error) {
+		return DuplicateParagraphsWithOptions(s, opts)
+	})
+}
+
+// NormalizeLineEndingsBytes is like NormalizeLineEndings but works on a
+// byte slice.
+func NormalizeLineEndingsBytes(b []byte, style LineEnding) []byte {
+	return apply(b, func(s string) string { return NormalizeLineEndings(s, style) })
+}
+
+// ProtectBytes is like Protect but works on a byte slice. If b contains no
+// protected region, fn is applied to b itself.
+func ProtectBytes(b []byte, fn func([]byte) []byte, regions ...Region) ([]byte, error) {
+	masked, saved, err := mask(view(b), regions)
+	if err != nil {
+		return b, err
+	}
+	if saved == nil {
+		return fn(b), nil
+	}
+	out, err := unmask(view(fn([]byte(masked))), saved)
+	if err != nil {
+		return b, err
+	}
+	return []byte(out), nil
+}
+
+// RunBytes is like Run but works on a byte slice. Steps added with Add or
+// AddFunc may keep the string they are given, so if any of them is
+// enabled, RunBytes runs the pipeline on a copy of b and always returns a
+// new slice. Only the built-in, word and regular expression steps share b.
+func (p *Pipeline) RunBytes(b []byte) ([]byte, error) {
+	steps, regions := p.enabled()
+	run := func(s string) (string, error) {
+		return p.runMasked(s, steps, regions)
+	}
+	if hasUserSteps(steps) {
+		out, err := run(string(b))
+		return []byte(out), err
+	}
+	return applyE(b, run)
+}
@@ -0,0 +1,202 @@
+package strdel
+
+import (
+	"strings"
+	"testing"
+)
+
+// byteVariants pairs every function with its Bytes variant.
+var byteVariants = []struct {
+	name  string
+	str   func(string) string
+	bytes func([]byte) []byte
+}{
+	{"Word",
+		func(s string) string { return Word(s, "foo") },
+		func(b []byte) []byte { return WordBytes(b, "foo") }},
+	{"WordWithOptions",
+		func(s string) string {
+			s, _ = WordWithOptions(s, "Foo", Options{IgnoreCase: true, Unicode: true})
+			return s
+		},
+		func(b []byte) []byte {
+			b, _ = WordWithOptionsBytes(b, "Foo", Options{IgnoreCase: true, Unicode: true})
+			return b
+		}},
+	{"Words",
+		func(s string) string { s, _ = Words(s, []string{"foo", "bar"}, Options{Tidy: true}); return s },
+		func(b []byte) []byte { b, _ = WordsBytes(b, []string{"foo", "bar"}, Options{Tidy: true}); return b }},
+	{"RegExp",
+		func(s string) string { return RegExp(s, `fo+`) },
+		func(b []byte) []byte { return RegExpBytes(b, `fo+`) }},
+	{"RegExpWithOptions",
+		func(s string) string { s, _ = RegExpWithOptions(s, "o.", Options{Literal: true}); return s },
+		func(b []byte) []byte { b, _ = RegExpWithOptionsBytes(b, "o.", Options{Literal: true}); return b }},
+	{"Numbering", Numbering, NumberingBytes},
+	{"NumberingWithOptions",
+		func(s string) string { return NumberingWithOptions(s, NumberingOptions{}) },
+		func(b []byte) []byte { return NumberingWithOptionsBytes(b, NumberingOptions{}) }},
+	{"TrailingSpaces", TrailingSpaces, TrailingSpacesBytes},
+	{"LeadingSpaces", LeadingSpaces, LeadingSpacesBytes},
+	{"TrailingSpacesWithOptions",
+		func(s string) string { return TrailingSpacesWithOptions(s, SpaceOptions{Class: UnicodeSpace}) },
+		func(b []byte) []byte { return TrailingSpacesWithOptionsBytes(b, SpaceOptions{Class: UnicodeSpace}) }},
+	{"LeadingSpacesWithOptions",
+		func(s string) string { return LeadingSpacesWithOptions(s, SpaceOptions{Class: UnicodeSpace}) },
+		func(b []byte) []byte { return LeadingSpacesWithOptionsBytes(b, SpaceOptions{Class: UnicodeSpace}) }},
+	{"NormalizeSpaces", NormalizeSpaces, NormalizeSpacesBytes},
+	{"InternalSpaces", InternalSpaces, InternalSpacesBytes},
+	{"NormalizeLineEndings",
+		func(s string) string { return NormalizeLineEndings(s, LF) },
+		func(b []byte) []byte { return NormalizeLineEndingsBytes(b, LF) }},
+	{"Dedent", Dedent, DedentBytes},
+	{"DedentWithOptions",
+		func(s string) string { return DedentWithOptions(s, DedentOptions{TabWidth: 4}) },
+		func(b []byte) []byte { return DedentWithOptionsBytes(b, DedentOptions{TabWidth: 4}) }},
+	{"EmptyLine", EmptyLine, EmptyLineBytes},
+	{"CollapseEmptyLines",
+		func(s string) string { return CollapseEmptyLines(s, 1) },
+		func(b []byte) []byte { return CollapseEmptyLinesBytes(b, 1) }},
+	{"CollapseEmptyLinesWithOptions",
+		func(s string) string {
+			return CollapseEmptyLinesWithOptions(s, 0, CollapseOptions{ClearBlank: true, Trailing: TrimEdge})
+		},
+		func(b []byte) []byte {
+			return CollapseEmptyLinesWithOptionsBytes(b, 0, CollapseOptions{ClearBlank: true, Trailing: TrimEdge})
+		}},
+	{"EmptyBrackets", EmptyBrackets, EmptyBracketsBytes},
+	{"EmptyLinesInMacros", EmptyLinesInMacros, EmptyLinesInMacrosBytes},
+	{"EmptyMacros",
+		func(s string) string { return EmptyMacros(s, 2) },
+		func(b []byte) []byte { return EmptyMacrosBytes(b, 2) }},
+	{"EmptyNestedMacros", EmptyNestedMacros, EmptyNestedMacrosBytes},
+	{"SpaceBeforeClosingBrackets", SpaceBeforeClosingBrackets, SpaceBeforeClosingBracketsBytes},
+	{"SpaceAfterOpeningBrackets", SpaceAfterOpeningBrackets, SpaceAfterOpeningBracketsBytes},
+	{"DuplicateLines", DuplicateLines, DuplicateLinesBytes},
+	{"DuplicateParagraphs", DuplicateParagraphs, DuplicateParagraphsBytes},
+}
+
+func Test_Bytes_haveAnyText_SameResultAsStrings(t *testing.T) {
+	texts := []string{
+		"",
+		"plain text without anything to delete",
+		"  1. foo bar  \n\n\t2. FOO o. \\emph{ \\textbf{} }\n\n",
+		"\\section{\n\nfoo} {}\\\\\n  end\n",
+	}
+	for _, v := range byteVariants {
+		for _, in := range texts {
+			b := []byte(in)
+			got := string(v.bytes(b))
+			if want := v.str(in); got != want {
+				t.Errorf("call %sBytes(%#v)\n\texp: %#v\n\n\tgot: %#v", v.name, in, want, got)
+			}
+			if string(b) != in {
+				t.Errorf("%sBytes changed its input to %#v", v.name, b)
+			}
+		}
+	}
+}
+
+func Test_Bytes_haveNothingToDelete_InputIsReturnedWithoutAllocation(t *testing.T) {
+	if raceEnabled {
+		t.Skip("the race detector allocates")
+	}
+	in := []byte("plain text without anything to delete\nsecond line")
+	for _, v := range byteVariants {
+		var out []byte
+		allocs := testing.AllocsPerRun(10, func() { out = v.bytes(in) })
+		if allocs != 0 {
+			t.Errorf("%sBytes: %v allocations, want 0", v.name, allocs)
+		}
+		if len(out) != len(in) || &out[0] != &in[0] {
+			t.Errorf("%sBytes did not return its input", v.name)
+		}
+	}
+
+	// A result that is part of the input is a slice of it.
+	in = []byte("text\r\n")
+	if out := EmptyLineBytes(in); string(out) != "text" || &out[0] != &in[0] {
+		t.Errorf("call EmptyLineBytes: want slice of input, got %#v", out)
+	}
+}
+
+func Test_ProtectBytes_haveProtectedRegion_RegionIsKept(t *testing.T) {
+	in := []byte("a  \n<pre>b  \n</pre>\n")
+	got, err := ProtectBytes(in, TrailingSpacesBytes, HTMLPre)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "a\n<pre>b  \n</pre>\n"; string(got) != want {
+		t.Errorf("call ProtectBytes(%#v)\n\texp: %#v\n\n\tgot: %#v", in, want, got)
+	}
+}
+
+func Test_WordsReportBytes_haveDeletions_TextDoesNotShareInput(t *testing.T) {
+	in := []byte("a foo b")
+	_, deletions, err := WordsReportBytes(in, []string{"foo"}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	copy(in, "xxxxxxx")
+	if len(deletions) != 1 || deletions[0].Text != "foo" {
+		t.Errorf("want deletion of foo, got %+v", deletions)
+	}
+}
+
+func Test_RunBytes_haveStepThatKeepsInput_InputIsNotShared(t *testing.T) {
+	var kept string
+	p := NewPipeline()
+	if err := p.Add("keep", func(s string) string { kept = s; return s }); err != nil {
+		t.Fatal(err)
+	}
+	in := []byte("a b")
+	got, err := p.RunBytes(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	copy(in, "xxx")
+	if kept != "a b" || string(got) != "a b" {
+		t.Errorf("want kept input and result a b, got %#v and %#v", kept, got)
+	}
+}
+
+func Test_RunBytes_haveOnlyBuiltinSteps_InputIsReturned(t *testing.T) {
+	p := NewPipeline()
+	if err := p.AddBuiltin("TrailingSpaces"); err != nil {
+		t.Fatal(err)
+	}
+	in := []byte("plain text\n")
+	got, err := p.RunBytes(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if &got[0] != &in[0] {
+		t.Errorf("want input itself, got a copy %#v", got)
+	}
+}
+
+func BenchmarkBytes_noMatch(b *testing.B) {
+	text := []byte(strings.Repeat("plain text without anything to delete\n", 1000))
+	for _, v := range byteVariants {
+		b.Run(v.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				v.bytes(text)
+			}
+		})
+	}
+}
+
+func BenchmarkString_noMatch(b *testing.B) {
+	text := []byte(strings.Repeat("plain text without anything to delete\n", 1000))
+	for _, v := range byteVariants {
+		b.Run(v.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				// Converting to string and back, as without the Bytes
+				// variants.
+				_ = []byte(v.str(string(text)))
+			}
+		})
+	}
+}
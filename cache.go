@@ -0,0 +1,118 @@
+package strdel
+
+import (
+	"container/list"
+	"regexp"
+	"sync"
+)
+
+// DefaultPatternCacheSize is the number of compiled patterns kept by the
+// package-wide pattern cache unless changed with SetPatternCacheSize.
+const DefaultPatternCacheSize = 512
+
+// CacheStats reports the state of the pattern cache.
+type CacheStats struct {
+	Hits      uint64 // lookups served from the cache
+	Misses    uint64 // lookups that had to compile the pattern
+	Evictions uint64 // patterns dropped to stay within Capacity
+	Len       int    // patterns currently cached
+	Capacity  int    // maximum number of cached patterns
+}
+
+// patternCache is a concurrency-safe LRU cache of compiled regular
+// expressions keyed by their source.
+type patternCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List // front is most recently used
+	entries  map[string]*list.Element
+	stats    CacheStats
+}
+
+type cacheEntry struct {
+	regExp string
+	re     *regexp.Regexp
+}
+
+var patterns = newPatternCache(DefaultPatternCacheSize)
+
+func newPatternCache(capacity int) *patternCache {
+	return &patternCache{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  map[string]*list.Element{},
+	}
+}
+
+// get returns the cached expression for regExp or nil.
+func (c *patternCache) get(regExp string) *regexp.Regexp {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[regExp]
+	if !ok {
+		c.stats.Misses++
+		return nil
+	}
+	c.stats.Hits++
+	c.order.MoveToFront(e)
+	return e.Value.(*cacheEntry).re
+}
+
+// add stores re under regExp, evicting the least recently used entries if
+// the cache is full.
+func (c *patternCache) add(regExp string, re *regexp.Regexp) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[regExp]; ok {
+		c.order.MoveToFront(e)
+		return
+	}
+	if c.capacity <= 0 {
+		return
+	}
+	c.entries[regExp] = c.order.PushFront(&cacheEntry{regExp: regExp, re: re})
+	c.shrink()
+}
+
+// shrink evicts entries until the cache fits its capacity. c.mu must be
+// held.
+func (c *patternCache) shrink() {
+	for c.order.Len() > c.capacity && c.order.Len() > 0 {
+		e := c.order.Back()
+		c.order.Remove(e)
+		delete(c.entries, e.Value.(*cacheEntry).regExp)
+		c.stats.Evictions++
+	}
+}
+
+func (c *patternCache) resize(capacity int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.capacity = capacity
+	c.shrink()
+}
+
+func (c *patternCache) snapshot() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Len = c.order.Len()
+	stats.Capacity = c.capacity
+	return stats
+}
+
+// PatternCacheStats returns the statistics of the cache of compiled patterns
+// shared by all strdel functions.
+func PatternCacheStats() CacheStats {
+	return patterns.snapshot()
+}
+
+// SetPatternCacheSize changes the number of compiled patterns the shared
+// cache keeps. A size of zero or less disables caching.
+func SetPatternCacheSize(size int) {
+	patterns.resize(size)
+}
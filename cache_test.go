@@ -0,0 +1,80 @@
+package strdel
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func Test_patternCache_haveMoreEntriesThanCapacity_LeastRecentlyUsedIsEvicted(t *testing.T) {
+	c := newPatternCache(2)
+
+	for _, p := range []string{`a`, `b`} {
+		re, err := compile(p, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.add(p, re)
+	}
+	c.get(`a`) // b is now least recently used
+	c.add(`c`, nil)
+
+	if c.get(`b`) != nil {
+		t.Errorf("want b evicted")
+	}
+	if c.get(`a`) == nil {
+		t.Errorf("want a cached")
+	}
+
+	want := CacheStats{Hits: 2, Misses: 1, Evictions: 1, Len: 2, Capacity: 2}
+	if got := c.snapshot(); got != want {
+		t.Errorf("stats\n\texp: %+v\n\tgot: %+v", want, got)
+	}
+
+	c.resize(0)
+	if got := c.snapshot(); got.Len != 0 || got.Evictions != 3 {
+		t.Errorf("want empty cache after resize, got %+v", got)
+	}
+}
+
+func Test_Word_haveRepeatedCalls_PatternIsCompiledOnce(t *testing.T) {
+	before := PatternCacheStats()
+	for i := 0; i < 3; i++ {
+		Word("cache me if you can: cache_test_word", "cache_test_word")
+	}
+	after := PatternCacheStats()
+
+	if misses := after.Misses - before.Misses; misses != 1 {
+		t.Errorf("want 1 miss, got %d", misses)
+	}
+	if hits := after.Hits - before.Hits; hits != 2 {
+		t.Errorf("want 2 hits, got %d", hits)
+	}
+}
+
+func Test_patternCache_haveConcurrentUse_StaysWithinCapacity(t *testing.T) {
+	c := newPatternCache(8)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				p := fmt.Sprintf("p%d", (g*i)%16)
+				if c.get(p) == nil {
+					c.add(p, nil)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	stats := c.snapshot()
+	if stats.Len > 8 {
+		t.Errorf("cache grew to %d entries", stats.Len)
+	}
+	if stats.Hits+stats.Misses != 800 {
+		t.Errorf("want 800 lookups, got %d", stats.Hits+stats.Misses)
+	}
+}
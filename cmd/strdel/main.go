@@ -0,0 +1,311 @@
+// Strdel deletes words, patterns, numbering and superfluous white space from
+// text files.
+//
+// Usage:
+//
+//	strdel [flags] [file ...]
+//
+// Without files strdel reads standard input and writes standard output.
+// With -i the files are rewritten in place, keeping a backup of each changed
+// file. With -check nothing is written; strdel lists the files that would
+// change and exits with status 1 if there are any.
+//
+// The rules of a file given with -rules run first, see strdel.LoadRules.
+// Then the cleanups selected by flags run in this order: words, regular
+// expressions, numbering, the LaTeX cleanups, leading spaces, trailing
+// spaces, empty lines and duplicate lines. The regions given with -protect
+// are left intact by both.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/frankMilde/strdel"
+)
+
+// Exit codes.
+const (
+	exitOK      = 0
+	exitChanged = 1 // -check found files that would change
+	exitError   = 2
+)
+
+// listFlag collects the values of a repeatable flag.
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+type config struct {
+	words, regExps listFlag
+	opts           strdel.Options
+
+	numbering, leading, trailing, emptyLines, dedupe bool
+
+	emptyBrackets, emptyMacros, emptyLinesInMacros bool
+	spaceBeforeClosing, spaceAfterOpening, latex   bool
+
+	protect string
+	rules   string
+
+	inPlace bool
+	backup  string
+	check   bool
+	verbose bool
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes strdel with args and returns the exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	var c config
+	fs := flag.NewFlagSet("strdel", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: strdel [flags] [file ...]\n")
+		fs.PrintDefaults()
+	}
+
+	fs.Var(&c.words, "word", "delete `word` at word boundaries (repeatable)")
+	fs.Var(&c.regExps, "regexp", "delete matches of `pattern` (repeatable)")
+	fs.BoolVar(&c.opts.Literal, "literal", false, "treat words and patterns as plain text")
+	fs.BoolVar(&c.opts.IgnoreCase, "ignore-case", false, "match words and patterns case-insensitively")
+	fs.BoolVar(&c.opts.Unicode, "unicode", false, "match words at Unicode word boundaries")
+	fs.BoolVar(&c.opts.Tidy, "tidy", false, "clean up the gaps left by deleted words and patterns")
+
+	fs.BoolVar(&c.numbering, "numbering", false, "delete numbering like \"3.\" at the start of lines")
+	fs.BoolVar(&c.leading, "leading", false, "delete spaces at the start of lines")
+	fs.BoolVar(&c.trailing, "trailing", false, "delete spaces at the end of lines")
+	fs.BoolVar(&c.emptyLines, "empty-lines", false, "delete empty lines")
+	fs.BoolVar(&c.dedupe, "dedupe", false, "delete repeated lines, keeping the first occurrence")
+
+	fs.BoolVar(&c.emptyBrackets, "empty-brackets", false, "LaTeX: empty brackets that only hold space or \\\\")
+	fs.BoolVar(&c.emptyMacros, "empty-macros", false, "LaTeX: delete macros with empty arguments")
+	fs.BoolVar(&c.emptyLinesInMacros, "empty-lines-in-macros", false, "LaTeX: delete blank lines in macro arguments")
+	fs.BoolVar(&c.spaceBeforeClosing, "space-before-closing", false, "LaTeX: delete space before closing brackets")
+	fs.BoolVar(&c.spaceAfterOpening, "space-after-opening", false, "LaTeX: delete space after opening brackets")
+	fs.BoolVar(&c.latex, "latex", false, "LaTeX: all of the LaTeX cleanups")
+
+	fs.StringVar(&c.protect, "protect", "", "comma separated `regions` left intact by rules and flags: "+
+		strings.Join(strdel.RegionNames(), ", "))
+	fs.StringVar(&c.rules, "rules", "", "run the rules of `file` before the cleanups selected by flags")
+
+	fs.BoolVar(&c.inPlace, "i", false, "rewrite files in place")
+	fs.StringVar(&c.backup, "backup", ".orig", "`suffix` of the backups written by -i; empty for none")
+	fs.BoolVar(&c.check, "check", false, "list files that would change and exit with status 1 if any")
+	fs.BoolVar(&c.verbose, "v", false, "log the changes of the LaTeX cleanups to standard error")
+
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	logger := log.New(stderr, "strdel: ", 0)
+
+	regions, err := c.regions()
+	if err != nil {
+		logError(logger, err)
+		return exitError
+	}
+	var pipelines []*strdel.Pipeline
+	if c.rules != "" {
+		p, err := strdel.LoadRulesFile(c.rules)
+		if err != nil {
+			logError(logger, err)
+			return exitError
+		}
+		pipelines = append(pipelines, p)
+	}
+	p, err := c.pipeline()
+	if err != nil {
+		logError(logger, err)
+		return exitError
+	}
+	pipelines = append(pipelines, p)
+	for _, p := range pipelines {
+		if err := p.Protect(regions...); err != nil {
+			logError(logger, err)
+			return exitError
+		}
+	}
+	if c.verbose {
+		strdel.SetLogger(log.New(stderr, "", 0))
+		defer strdel.SetLogger(nil)
+	}
+
+	files := fs.Args()
+	if len(files) == 0 {
+		if c.inPlace {
+			logger.Print("-i needs files")
+			return exitError
+		}
+		files = []string{"-"}
+	}
+
+	code := exitOK
+	for _, name := range files {
+		changed, err := c.process(pipelines, name, stdin, stdout)
+		switch {
+		case err != nil:
+			logError(logger, err)
+			code = exitError
+		case changed && c.check:
+			fmt.Fprintln(stdout, name)
+			if code == exitOK {
+				code = exitChanged
+			}
+		}
+	}
+	return code
+}
+
+// pipeline builds the pipeline of the selected cleanups.
+func (c *config) pipeline() (*strdel.Pipeline, error) {
+	p := strdel.NewPipeline()
+	if len(c.words) > 0 {
+		if err := p.AddWords("word", c.words, c.opts); err != nil {
+			return nil, err
+		}
+	}
+	for i, re := range c.regExps {
+		if err := p.AddRegExp(fmt.Sprintf("regexp %d", i+1), re, c.opts); err != nil {
+			return nil, err
+		}
+	}
+
+	builtins := []struct {
+		name     string
+		selected bool
+	}{
+		{"Numbering", c.numbering},
+		{"EmptyLinesInMacros", c.emptyLinesInMacros || c.latex},
+		{"EmptyMacros", c.emptyMacros || c.latex},
+		{"EmptyBrackets", c.emptyBrackets || c.latex},
+		{"SpaceAfterOpeningBrackets", c.spaceAfterOpening || c.latex},
+		{"SpaceBeforeClosingBrackets", c.spaceBeforeClosing || c.latex},
+		{"LeadingSpaces", c.leading},
+		{"TrailingSpaces", c.trailing},
+		{"EmptyLine", c.emptyLines},
+	}
+	for _, b := range builtins {
+		if !b.selected {
+			continue
+		}
+		if err := p.AddBuiltin(b.name); err != nil {
+			return nil, err
+		}
+	}
+	if c.dedupe {
+		if err := p.Add("Dedupe", strdel.DuplicateLines); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// regions returns the regions selected by -protect.
+func (c *config) regions() ([]strdel.Region, error) {
+	if c.protect == "" {
+		return nil, nil
+	}
+	var regions []strdel.Region
+	for _, name := range strings.Split(c.protect, ",") {
+		r, ok := strdel.RegionNamed(strings.TrimSpace(name))
+		if !ok {
+			return nil, fmt.Errorf("unknown region %q, want one of %s",
+				name, strings.Join(strdel.RegionNames(), ", "))
+		}
+		regions = append(regions, r)
+	}
+	return regions, nil
+}
+
+// logError logs every line of err, without the prefix of the errors of
+// package strdel, which logger adds itself.
+func logError(logger *log.Logger, err error) {
+	for _, line := range strings.Split(err.Error(), "\n") {
+		logger.Print(strings.TrimPrefix(line, "strdel: "))
+	}
+}
+
+// process cleans the file called name, or standard input for "-", and
+// reports whether it changed.
+func (c *config) process(pipelines []*strdel.Pipeline, name string, stdin io.Reader, stdout io.Writer) (bool, error) {
+	var in []byte
+	var err error
+	if name == "-" {
+		in, err = io.ReadAll(stdin)
+	} else {
+		in, err = os.ReadFile(name)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	out := in
+	for _, p := range pipelines {
+		if out, err = p.RunBytes(out); err != nil {
+			return false, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	changed := !bytes.Equal(in, out)
+
+	switch {
+	case c.check:
+	case c.inPlace && name != "-":
+		if changed {
+			err = rewrite(name, in, out, c.backup)
+		}
+	default:
+		_, err = stdout.Write(out)
+	}
+	return changed, err
+}
+
+// rewrite replaces the content old of the file called name by new, after
+// saving old to name+backup unless backup is empty.
+func rewrite(name string, old, new []byte, backup string) error {
+	info, err := os.Stat(name)
+	if err != nil {
+		return err
+	}
+	if backup != "" {
+		if err := os.WriteFile(name+backup, old, info.Mode().Perm()); err != nil {
+			return err
+		}
+	}
+
+	// Write to a temporary file first, so name is never left half written.
+	tmp, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".strdel*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(new)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), info.Mode().Perm())
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), name)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("%s not rewritten: %w", name, err)
+	}
+	return nil
+}
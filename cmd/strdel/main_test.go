@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_run_haveStdin_CleanedTextIsWritten(t *testing.T) {
+	tests := []struct {
+		args []string
+		in   string
+		want string
+	}{
+		{
+			args: []string{"-word", "foo", "-word", "bar", "-tidy", "-trailing"},
+			in:   "a foo b  \nbar\nc\n",
+			want: "a b\nc\n",
+		},
+		{
+			args: []string{"-regexp", `[0-9]{2}`, "-numbering", "-leading"},
+			in:   "  1. item 42\n",
+			want: "item \n",
+		},
+		{
+			args: []string{"-empty-lines", "-dedupe"},
+			in:   "a\n\nb\na\n\n",
+			want: "a\nb",
+		},
+		{
+			args: []string{"-latex", "-leading", "-trailing", "-protect", "latex-verbatim"},
+			in:   "\\emph{} x \\textbf{a\n\nb }\n\\begin{verbatim}\n\\emph{}  \n\\end{verbatim}\n",
+			want: "x \\textbf{a\nb}\n\\begin{verbatim}\n\\emph{}  \n\\end{verbatim}\n",
+		},
+	}
+
+	for _, test := range tests {
+		var stdout, stderr bytes.Buffer
+		code := run(test.args, strings.NewReader(test.in), &stdout, &stderr)
+		if code != exitOK {
+			t.Errorf("run %q: exit code %d: %s", test.args, code, stderr.String())
+		}
+		if got := stdout.String(); got != test.want {
+			t.Errorf("run %q on %#v\n\texp: %#v\n\n\tgot: %#v", test.args, test.in, test.want, got)
+		}
+	}
+}
+
+func Test_run_haveFiles_InPlaceAndCheckWork(t *testing.T) {
+	dir := t.TempDir()
+	dirty := filepath.Join(dir, "dirty.txt")
+	clean := filepath.Join(dir, "clean.txt")
+	if err := os.WriteFile(dirty, []byte("a  \n"), 0o640); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(clean, []byte("a\n"), 0o640); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-trailing", "-check", dirty, clean}, nil, &stdout, &stderr); code != exitChanged {
+		t.Errorf("-check: want exit code %d, got %d", exitChanged, code)
+	}
+	if got := stdout.String(); got != dirty+"\n" {
+		t.Errorf("-check listed %#v", got)
+	}
+	if code := run([]string{"-trailing", "-check", clean}, nil, &stdout, &stderr); code != exitOK {
+		t.Errorf("-check of clean file: want exit code 0, got %d", code)
+	}
+
+	if code := run([]string{"-trailing", "-i", dirty, clean}, nil, &stdout, &stderr); code != exitOK {
+		t.Fatalf("-i: exit code %d: %s", code, stderr.String())
+	}
+	for name, want := range map[string]string{
+		dirty:           "a\n",
+		dirty + ".orig": "a  \n",
+		clean:           "a\n",
+	} {
+		got, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("%s: want %#v, got %#v", name, want, got)
+		}
+	}
+	if _, err := os.Stat(clean + ".orig"); !os.IsNotExist(err) {
+		t.Errorf("unchanged file was backed up")
+	}
+	if info, err := os.Stat(dirty); err != nil || info.Mode().Perm() != 0o640 {
+		t.Errorf("rewritten file lost its mode: %v, %v", info, err)
+	}
+}
+
+func Test_run_haveRulesFile_RulesRunBeforeFlags(t *testing.T) {
+	rules := filepath.Join(t.TempDir(), "rules.json")
+	err := os.WriteFile(rules, []byte(`{"rules": [
+		{"name": "x", "kind": "regexp", "pattern": "x+", "flags": ["tidy"]}
+	]}`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-rules", rules, "-word", "a"}, strings.NewReader("a xx b\n"), &stdout, &stderr)
+	if code != exitOK {
+		t.Fatalf("exit code %d: %s", code, stderr.String())
+	}
+	if got, want := stdout.String(), " b\n"; got != want {
+		t.Errorf("want %#v, got %#v", want, got)
+	}
+}
+
+func Test_run_haveRulesAndProtect_RegionsAreKeptByRules(t *testing.T) {
+	rules := filepath.Join(t.TempDir(), "rules.json")
+	err := os.WriteFile(rules, []byte(`{"rules": [{"name": "x", "kind": "regexp", "pattern": "x+"}]}`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-rules", rules, "-protect", "html-pre"}, strings.NewReader("axx <pre>xx</pre>\n"), &stdout, &stderr)
+	if code != exitOK {
+		t.Fatalf("exit code %d: %s", code, stderr.String())
+	}
+	if got, want := stdout.String(), "a <pre>xx</pre>\n"; got != want {
+		t.Errorf("want %#v, got %#v", want, got)
+	}
+}
+
+func Test_run_haveInvalidRules_ErrorsAreLogged(t *testing.T) {
+	rules := filepath.Join(t.TempDir(), "rules.json")
+	err := os.WriteFile(rules, []byte(`{"rules": [
+		{"name": "a", "kind": "regexp", "pattern": "("},
+		{"name": "b", "kind": "nonsense"}
+	]}`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-rules", rules}, strings.NewReader(""), &stdout, &stderr); code != exitError {
+		t.Errorf("want exit code %d, got %d", exitError, code)
+	}
+	lines := strings.Split(strings.TrimSuffix(stderr.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Errorf("want an error line for each rule, got %#v", stderr.String())
+	}
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "strdel: "+rules+":") {
+			t.Errorf("want error of %s with one strdel prefix, got %#v", rules, line)
+		}
+	}
+}
+
+func Test_run_haveBadArguments_ExitWithError(t *testing.T) {
+	tests := [][]string{
+		{"-regexp", "a["},
+		{"-protect", "nonsense"},
+		{"-i"},
+		{"-no-such-flag"},
+		{"-rules", filepath.Join(os.TempDir(), "strdel-no-rules.json")},
+		{filepath.Join(os.TempDir(), "strdel-does-not-exist")},
+	}
+	for _, args := range tests {
+		var stdout, stderr bytes.Buffer
+		if code := run(args, strings.NewReader(""), &stdout, &stderr); code != exitError {
+			t.Errorf("run %q: want exit code %d, got %d", args, exitError, code)
+		}
+		if stderr.Len() == 0 {
+			t.Errorf("run %q: no error message", args)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package strdel
+
+import "strings"
+
+// An EdgeMode says what CollapseEmptyLinesWithOptions does with the blank
+// lines at the start or at the end of a text.
+type EdgeMode int
+
+const (
+	// CollapseEdge collapses them like the blank lines between paragraphs.
+	CollapseEdge EdgeMode = iota
+	// TrimEdge deletes them.
+	TrimEdge
+	// KeepEdge leaves them alone.
+	KeepEdge
+)
+
+// CollapseOptions control CollapseEmptyLinesWithOptions. The zero value
+// gives the behavior of CollapseEmptyLines.
+type CollapseOptions struct {
+	// ClearBlank deletes the white space of the blank lines that are
+	// kept, so they become empty.
+	ClearBlank bool
+
+	// Leading and Trailing handle the blank lines before the first and
+	// after the last line of text. The line break of the last line of
+	// text is kept.
+	Leading, Trailing EdgeMode
+
+	// Class is the white space of blank lines.
+	Class WhitespaceClass
+}
+
+// CollapseEmptyLines reduces every run of blank lines in string s to at
+// most max lines, keeping the first lines of the run. Blank lines contain
+// nothing but spaces, tabs and form feeds, as for EmptyLine, but unlike
+// EmptyLine it keeps paragraphs apart.
+// Example: "a\n\n\n\nb" --> "a\n\nb" for max 1
+func CollapseEmptyLines(s string, max int) string {
+	return CollapseEmptyLinesWithOptions(s, max, CollapseOptions{})
+}
+
+// CollapseEmptyLinesWithOptions is like CollapseEmptyLines, configured by
+// opts.
+func CollapseEmptyLinesWithOptions(s string, max int, opts CollapseOptions) string {
+	if max < 0 {
+		max = 0
+	}
+
+	var b strings.Builder
+	last := 0 // end of the text copied to b
+
+	// emit keeps the lines of the run of blank lines from start to end
+	// up to keep.
+	emit := func(start, keep, end int) {
+		clear := opts.ClearBlank && !onlyLineBreaks(s[start:keep])
+		if keep == end && !clear {
+			return
+		}
+		if last == 0 {
+			b.Grow(len(s))
+		}
+		b.WriteString(s[last:start])
+		for kept := s[start:keep]; len(kept) > 0; {
+			line, br := nextLine(kept)
+			if !clear {
+				b.WriteString(line)
+			}
+			b.WriteString(br)
+			kept = kept[len(line)+len(br):]
+		}
+		last = end
+	}
+
+	start, afterMax, n := 0, 0, 0 // run of n blank lines
+	for i := 0; i < len(s); {
+		line, br := nextLine(s[i:])
+		end := i + len(line) + len(br)
+		if !blankLine(line, opts.Class) {
+			if n > 0 {
+				keep := collapsed(n, max, afterMax, i)
+				if start == 0 {
+					keep = edge(opts.Leading, start, keep, i)
+				}
+				emit(start, keep, i)
+			}
+			n = 0
+			i = end
+			continue
+		}
+		if n == 0 {
+			start, afterMax = i, i
+		}
+		if n++; n <= max {
+			afterMax = end
+		}
+		i = end
+	}
+	if n > 0 {
+		keep := collapsed(n, max, afterMax, len(s))
+		trailing := edge(opts.Trailing, start, keep, len(s))
+		if leading := edge(opts.Leading, start, keep, len(s)); start == 0 && leading < trailing {
+			trailing = leading
+		}
+		emit(start, trailing, len(s))
+	}
+
+	if last == 0 {
+		return s
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// collapsed returns the end of the first max lines of a run of n blank
+// lines that ends at end, where the first max lines end at afterMax.
+func collapsed(n, max, afterMax, end int) int {
+	if n <= max {
+		return end
+	}
+	return afterMax
+}
+
+// edge returns the end of the lines kept of a run of blank lines from
+// start to end at an edge of the text, where collapsing keeps them up to
+// collapsed.
+func edge(mode EdgeMode, start, collapsed, end int) int {
+	switch mode {
+	case TrimEdge:
+		return start
+	case KeepEdge:
+		return end
+	}
+	return collapsed
+}
+
+// onlyLineBreaks reports whether s consists of line breaks only.
+func onlyLineBreaks(s string) bool {
+	for len(s) > 0 {
+		line, br := nextLine(s)
+		if line != "" {
+			return false
+		}
+		s = s[len(br):]
+	}
+	return true
+}
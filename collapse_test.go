@@ -0,0 +1,63 @@
+package strdel
+
+import "testing"
+
+func Test_CollapseEmptyLines_haveBlankLineRuns_RunsAreCollapsed(t *testing.T) {
+	tests := []struct {
+		in, want string
+		max      int
+	}{
+		{in: "", want: "", max: 1},
+		{in: "a\nb\n", want: "a\nb\n", max: 0},
+		{in: "a\n\n\n\nb\n", want: "a\n\nb\n", max: 1},
+		{in: "a\n\n \n\t\n\nb", want: "a\n\n \nb", max: 2},
+		{in: "a\n\n\nb\n\nc\n", want: "a\nb\nc\n", max: 0},
+		{in: "a\n\n\nb\n", want: "a\nb\n", max: -1},
+		{in: "\n\n\na\n\n\n", want: "\na\n\n", max: 1},
+		{in: "a\r\n\r\n\r\n\u2028b", want: "a\r\n\r\nb", max: 1},
+		{in: "\\section{A}\n\n\n\nText.\n\n\nMore.\n", want: "\\section{A}\n\nText.\n\nMore.\n", max: 1},
+	}
+	for _, test := range tests {
+		if got := CollapseEmptyLines(test.in, test.max); got != test.want {
+			t.Errorf("call CollapseEmptyLines(%#v, %d)\n\texp: %#v\n\n\tgot: %#v", test.in, test.max, test.want, got)
+		}
+	}
+}
+
+func Test_CollapseEmptyLinesWithOptions_haveOptions_EdgesAndBlanksAreHandled(t *testing.T) {
+	in := "\n \n\t\na\n \n\t\n\nb\n\n  \n\n"
+	tests := []struct {
+		opts CollapseOptions
+		want string
+	}{
+		{CollapseOptions{}, "\n \na\n \n\t\nb\n\n  \n"},
+		{CollapseOptions{ClearBlank: true}, "\n\na\n\n\nb\n\n\n"},
+		{CollapseOptions{Leading: TrimEdge, Trailing: TrimEdge}, "a\n \n\t\nb\n"},
+		{CollapseOptions{Leading: KeepEdge, Trailing: KeepEdge}, "\n \n\t\na\n \n\t\nb\n\n  \n\n"},
+		{CollapseOptions{Leading: KeepEdge, ClearBlank: true}, "\n\n\na\n\n\nb\n\n\n"},
+	}
+	for _, test := range tests {
+		if got := CollapseEmptyLinesWithOptions(in, 2, test.opts); got != test.want {
+			t.Errorf("call CollapseEmptyLinesWithOptions(%#v, 2, %+v)\n\texp: %#v\n\n\tgot: %#v",
+				in, test.opts, test.want, got)
+		}
+	}
+
+	blank := " \n\n \n"
+	if got := CollapseEmptyLinesWithOptions(blank, 1, CollapseOptions{Leading: KeepEdge, Trailing: TrimEdge}); got != "" {
+		t.Errorf("want blank text trimmed, got %#v", got)
+	}
+}
+
+func Test_CollapseEmptyLinesWithOptions_haveUnicodeSpaceLines_ClassDecidesBlank(t *testing.T) {
+	in := "a\n\n\u00A0\n\u3000\nb\n"
+	if got, want := CollapseEmptyLines(in, 1), in; got != want {
+		t.Errorf("call CollapseEmptyLines(%#v, 1)\n\texp: %#v\n\n\tgot: %#v", in, want, got)
+	}
+	if got, want := CollapseEmptyLinesWithOptions(in, 1, CollapseOptions{Class: UnicodeSpace}), "a\n\nb\n"; got != want {
+		t.Errorf("call CollapseEmptyLinesWithOptions(%#v, 1, UnicodeSpace)\n\texp: %#v\n\n\tgot: %#v", in, want, got)
+	}
+	if got, want := EmptyLine(in), "a\n\u00A0\n\u3000\nb"; got != want {
+		t.Errorf("call EmptyLine(%#v)\n\texp: %#v\n\n\tgot: %#v", in, want, got)
+	}
+}
@@ -0,0 +1,99 @@
+package strdel
+
+import "strings"
+
+// DedentOptions control DedentWithOptions. The zero value gives the
+// behavior of Dedent.
+type DedentOptions struct {
+	// TabWidth is the distance of tab stops in columns. Default 8.
+	TabWidth int
+
+	// Prefix re-indents the text: it is added to every line that is not
+	// blank after the common indentation was removed.
+	Prefix string
+}
+
+const defaultTabWidth = 8
+
+// Dedent removes the longest common indentation of the lines of string s
+// that are not blank, so the lines keep their indentation relative to each
+// other. Unlike LeadingSpaces it keeps nested lists and code intact.
+// Indentation is made of spaces and tabs, which advance to the next tab
+// stop; a tab that is only partly removed is replaced by spaces.
+// Example: "    a\n      b" --> "a\n  b"
+func Dedent(s string) string {
+	return DedentWithOptions(s, DedentOptions{})
+}
+
+// DedentWithOptions is like Dedent, configured by opts.
+func DedentWithOptions(s string, opts DedentOptions) string {
+	if opts.TabWidth <= 0 {
+		opts.TabWidth = defaultTabWidth
+	}
+
+	common := -1
+	for i := 0; i < len(s); {
+		line, br := nextLine(s[i:])
+		if !blankLine(line, ASCIISpace) {
+			if w := indentWidth(line, opts.TabWidth); common < 0 || w < common {
+				common = w
+			}
+		}
+		i += len(line) + len(br)
+	}
+	if common <= 0 && opts.Prefix == "" {
+		return s
+	}
+
+	return mapLines(s, func(line string, _ bool) string {
+		line = removeColumns(line, common, opts.TabWidth)
+		if opts.Prefix == "" || blankLine(line, ASCIISpace) {
+			return line
+		}
+		return opts.Prefix + line
+	})
+}
+
+// indentWidth returns the width in columns of the indentation of line.
+func indentWidth(line string, tabWidth int) int {
+	col := 0
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case ' ':
+			col++
+		case '\t':
+			col = (col/tabWidth + 1) * tabWidth
+		default:
+			return col
+		}
+	}
+	return col
+}
+
+// removeColumns removes up to cols columns of indentation from line.
+func removeColumns(line string, cols int, tabWidth int) string {
+	if cols <= 0 {
+		return line
+	}
+	n := len(line) - len(strings.TrimLeft(line, " \t"))
+	width := indentWidth(line, tabWidth)
+	if width <= cols {
+		return line[n:]
+	}
+	if cols%tabWidth != 0 && strings.IndexByte(line[:n], '\t') >= 0 {
+		// Tabs that are partly removed or follow the removed columns
+		// would end at other columns, so the indentation becomes spaces.
+		return strings.Repeat(" ", width-cols) + line[n:]
+	}
+
+	col, i := 0, 0
+	for col < cols {
+		if line[i] == '\t' {
+			col = (col/tabWidth + 1) * tabWidth
+		} else {
+			col++
+		}
+		i++
+	}
+	return line[i:]
+}
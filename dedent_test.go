@@ -0,0 +1,46 @@
+package strdel
+
+import "testing"
+
+func Test_Dedent_haveIndentedText_CommonIndentationIsRemoved(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"a\n  b\n", "a\n  b\n"},
+		{"    <ul>\n      <li>Services\\GameService.cs</li>\n    </ul>\n",
+			"<ul>\n  <li>Services\\GameService.cs</li>\n</ul>\n"},
+		{"    a\n\n      b\n  \n", "a\n\n  b\n\n"},
+		{"\tfunc f() {\n\t\treturn\n\t}", "func f() {\n\treturn\n}"},
+		{"\ta\n        b\r\n", "a\nb\r\n"},
+		{"\ta\n    b\n", "    a\nb\n"},
+		{"    a\n    \tb", "a\n    b"},
+		{"  a\n  \t  b\n", "a\n        b\n"},
+		{"  \n \n", "  \n \n"},
+	}
+	for _, test := range tests {
+		if got := Dedent(test.in); got != test.want {
+			t.Errorf("call Dedent(%#v)\n\texp: %#v\n\n\tgot: %#v", test.in, test.want, got)
+		}
+	}
+}
+
+func Test_DedentWithOptions_haveOptions_TabsAndPrefixAreUsed(t *testing.T) {
+	tests := []struct {
+		in, want string
+		opts     DedentOptions
+	}{
+		{"\ta\n    b\n", "a\nb\n", DedentOptions{TabWidth: 4}},
+		{"  \ta\n    b\n", "a\nb\n", DedentOptions{TabWidth: 4}},
+		{"\t\ta\n      b\n", "  a\nb\n", DedentOptions{TabWidth: 4}},
+		{"    a\n    \tb\n", "a\n\tb\n", DedentOptions{TabWidth: 4}},
+		{"  a\n  \tb\n", "a\n  b\n", DedentOptions{TabWidth: 4}},
+		{"    x := 1\n\n      y\n", "> x := 1\n\n>   y\n", DedentOptions{Prefix: "> "}},
+		{"a\nb", "\ta\n\tb", DedentOptions{Prefix: "\t"}},
+	}
+	for _, test := range tests {
+		if got := DedentWithOptions(test.in, test.opts); got != test.want {
+			t.Errorf("call DedentWithOptions(%#v, %+v)\n\texp: %#v\n\n\tgot: %#v", test.in, test.opts, test.want, got)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package strdel
+
+// DuplicatesFunc deletes the items of a slice whose key was seen before,
+// keeping the first item of every key in order. Keys like strings.ToLower
+// or strings.TrimSpace dedupe case-insensitively or by trimmed content.
+func DuplicatesFunc[T any, K comparable](items []T, key func(T) K) []T {
+	kept, _ := DuplicatesWithCounts(items, key)
+	return kept
+}
+
+// Occurrence describes an item kept by DuplicatesWithCounts.
+type Occurrence struct {
+	Index int // index of the item in the input
+	Count int // number of items with the same key, including the kept one
+}
+
+// DuplicatesWithCounts is like DuplicatesFunc but also returns, for every
+// kept item, where it was first seen and how often its key occurred.
+// Count-1 copies of it were dropped.
+func DuplicatesWithCounts[T any, K comparable](items []T, key func(T) K) ([]T, []Occurrence) {
+	seen := make(map[K]int, len(items)) // key to index in kept
+	kept := []T{}
+	var occurrences []Occurrence
+
+	for i, item := range items {
+		k := key(item)
+		if j, ok := seen[k]; ok {
+			occurrences[j].Count++
+			continue
+		}
+		seen[k] = len(kept)
+		kept = append(kept, item)
+		occurrences = append(occurrences, Occurrence{Index: i, Count: 1})
+	}
+	return kept, occurrences
+}
+
+// DedupeOptions control DuplicateLinesWithOptions and
+// DuplicateParagraphsWithOptions. The zero value gives the behavior of
+// DuplicateLines and DuplicateParagraphs.
+type DedupeOptions struct {
+	// Consecutive deletes only repeats that directly follow each other,
+	// like uniq. A blank line separates lines that are repeated.
+	Consecutive bool
+
+	// Regions are left intact. A line or paragraph that contains a
+	// region is never a duplicate.
+	Regions []Region
+
+	// Class is the white space of blank lines.
+	Class WhitespaceClass
+}
+
+// DuplicateLines deletes lines of s that occurred before, together with
+// their line break. Lines are compared without their line break; blank
+// lines, made of spaces, tabs and form feeds, are never deleted.
+func DuplicateLines(s string) string {
+	return dedupe(s, nextLineUnit, DedupeOptions{})
+}
+
+// DuplicateLinesWithOptions is like DuplicateLines, configured by opts.
+func DuplicateLinesWithOptions(s string, opts DedupeOptions) (string, error) {
+	return Protect(s, func(s string) string {
+		return dedupe(s, nextLineUnit, opts)
+	}, opts.Regions...)
+}
+
+// DuplicateParagraphs deletes paragraphs of s that occurred before,
+// together with the blank lines that follow them. Paragraphs are separated
+// by blank lines and compared line by line.
+func DuplicateParagraphs(s string) string {
+	return dedupe(s, nextParagraphUnit, DedupeOptions{})
+}
+
+// DuplicateParagraphsWithOptions is like DuplicateParagraphs, configured
+// by opts.
+func DuplicateParagraphsWithOptions(s string, opts DedupeOptions) (string, error) {
+	return Protect(s, func(s string) string {
+		return dedupe(s, nextParagraphUnit, opts)
+	}, opts.Regions...)
+}
+
+// dedupe deletes the repeated units of s that next splits off its input,
+// as configured by opts.Consecutive and opts.Class. A unit is a body,
+// which is compared, and a separator. If the last unit of s is deleted,
+// the last kept unit gets its separator, so the text still ends the same
+// way.
+func dedupe(s string, next func(string, WhitespaceClass) (body, sep string, blank bool), opts DedupeOptions) string {
+	seen := map[string]struct{}{}
+	prev, hasPrev := "", false
+
+	var out []byte
+	last := 0 // end of the text copied to out
+	keptSep, finalSep, finalDropped := "", "", false
+
+	for i := 0; i < len(s); {
+		body, sep, blank := next(s[i:], opts.Class)
+		end := i + len(body) + len(sep)
+
+		dup := false
+		switch {
+		case blank:
+			hasPrev = false
+		case opts.Consecutive:
+			dup = hasPrev && body == prev
+			prev, hasPrev = body, true
+		default:
+			_, dup = seen[body]
+			seen[body] = struct{}{}
+		}
+
+		if dup {
+			out = append(out, s[last:i]...)
+			last = end
+		} else {
+			keptSep = sep
+		}
+		finalSep, finalDropped = sep, dup
+		i = end
+	}
+	if last == 0 {
+		return s
+	}
+
+	out = append(out, s[last:]...)
+	if finalDropped {
+		out = append(out[:len(out)-len(keptSep)], finalSep...)
+	}
+	return string(out)
+}
+
+// nextLineUnit splits the first line of s into its content and line break.
+func nextLineUnit(s string, class WhitespaceClass) (string, string, bool) {
+	content, br := nextLine(s)
+	return content, br, blankLine(content, class)
+}
+
+// nextParagraphUnit splits the first paragraph of s into its lines up to
+// the last line break, and that line break with the blank lines that
+// follow. Blank lines at the start of s form a blank unit of their own.
+func nextParagraphUnit(s string, class WhitespaceClass) (string, string, bool) {
+	i, end := 0, 0
+	for i < len(s) {
+		content, br := nextLine(s[i:])
+		if blankLine(content, class) {
+			break
+		}
+		end = i + len(content)
+		i += len(content) + len(br)
+	}
+	for i < len(s) {
+		content, br := nextLine(s[i:])
+		if !blankLine(content, class) {
+			break
+		}
+		i += len(content) + len(br)
+	}
+	return s[:end], s[end:i], end == 0
+}
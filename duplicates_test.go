@@ -0,0 +1,145 @@
+package strdel
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_DuplicatesFunc_haveKeyFunc_FirstOfEveryKeyIsKept(t *testing.T) {
+	in := []string{"Apple", " apple", "pear", "APPLE ", "Pear", "plum"}
+
+	got := DuplicatesFunc(in, func(s string) string {
+		return strings.ToLower(strings.TrimSpace(s))
+	})
+	if want := []string{"Apple", "pear", "plum"}; !reflect.DeepEqual(want, got) {
+		t.Errorf("call DuplicatesFunc(%#v)\n\texp: %#v\n\n\tgot: %#v", in, want, got)
+	}
+
+	if got := DuplicatesFunc(nil, strings.ToLower); got == nil || len(got) != 0 {
+		t.Errorf("want empty slice for nil input, got %#v", got)
+	}
+	if got, want := Duplicates([]string{"a", "b", "a"}), []string{"a", "b"}; !reflect.DeepEqual(want, got) {
+		t.Errorf("call Duplicates\n\texp: %#v\n\n\tgot: %#v", want, got)
+	}
+}
+
+func Test_DuplicatesWithCounts_haveURLs_CountsAndIndicesAreReturned(t *testing.T) {
+	type link struct {
+		text string
+		href string
+	}
+	in := []link{
+		{"home", "https://example.com/"},
+		{"docs", "https://example.com/docs"},
+		{"Home", "HTTPS://EXAMPLE.COM/"},
+		{"docs again", "https://example.com/docs"},
+		{"bad", "%zz"},
+		{"docs", "https://Example.com/docs"},
+	}
+	normalized := func(l link) string {
+		u, err := url.Parse(l.href)
+		if err != nil {
+			return l.href
+		}
+		return strings.ToLower(u.Scheme) + "://" + strings.ToLower(u.Host) + u.Path
+	}
+
+	kept, occurrences := DuplicatesWithCounts(in, normalized)
+	if want := []link{in[0], in[1], in[4]}; !reflect.DeepEqual(want, kept) {
+		t.Errorf("kept\n\texp: %#v\n\n\tgot: %#v", want, kept)
+	}
+	want := []Occurrence{{Index: 0, Count: 2}, {Index: 1, Count: 3}, {Index: 4, Count: 1}}
+	if !reflect.DeepEqual(want, occurrences) {
+		t.Errorf("occurrences\n\texp: %+v\n\n\tgot: %+v", want, occurrences)
+	}
+}
+
+func Test_DuplicateLines_haveRepeatedLines_FirstOccurrencesAreKept(t *testing.T) {
+	tests := []struct {
+		in, want    string
+		consecutive bool
+	}{
+		{in: "", want: ""},
+		{in: "a\nb\nc\n", want: "a\nb\nc\n"},
+		{in: "img\ncaption\nimg\ncaption\ntext\n", want: "img\ncaption\ntext\n"},
+		{in: "a\r\nb\na\n", want: "a\r\nb\n"},
+		{in: "a\nb\na", want: "a\nb"},
+		{in: "a\nb\n\na\n\nb", want: "a\nb\n\n"},
+		{in: "a\na\nb\na\n", want: "a\nb\na\n", consecutive: true},
+		{in: "a\n\na\na\n", want: "a\n\na\n", consecutive: true},
+		{in: "a\n\u00A0\n\u00A0\n", want: "a\n\u00A0\n"},
+	}
+	for _, test := range tests {
+		got, err := DuplicateLinesWithOptions(test.in, DedupeOptions{Consecutive: test.consecutive})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.want {
+			t.Errorf("call DuplicateLines(%#v), consecutive %v\n\texp: %#v\n\n\tgot: %#v",
+				test.in, test.consecutive, test.want, got)
+		}
+		if !test.consecutive && DuplicateLines(test.in) != got {
+			t.Errorf("DuplicateLines and DuplicateLinesWithOptions differ for %#v", test.in)
+		}
+	}
+}
+
+func Test_DuplicateParagraphs_haveRepeatedParagraphs_FirstOccurrencesAreKept(t *testing.T) {
+	tests := []struct {
+		in, want    string
+		consecutive bool
+	}{
+		{in: "\n\na\nb\n\nc\n", want: "\n\na\nb\n\nc\n"},
+		{in: "a\nb\n\nc\n\n\na\nb\n\nd\n", want: "a\nb\n\nc\n\n\nd\n"},
+		{in: "a\nb\n\na\n\nb\n", want: "a\nb\n\na\n\nb\n"},
+		{in: "a\n\nb\n\na\n", want: "a\n\nb\n"},
+		{in: "a\r\n\r\nb\r\n\r\na", want: "a\r\n\r\nb"},
+		{in: "a\n\na\n\nb\n\na\n", want: "a\n\nb\n\na\n", consecutive: true},
+	}
+	for _, test := range tests {
+		got, err := DuplicateParagraphsWithOptions(test.in, DedupeOptions{Consecutive: test.consecutive})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.want {
+			t.Errorf("call DuplicateParagraphs(%#v), consecutive %v\n\texp: %#v\n\n\tgot: %#v",
+				test.in, test.consecutive, test.want, got)
+		}
+	}
+}
+
+func Test_DuplicateLinesWithOptions_haveCodeBlock_BlockIsKept(t *testing.T) {
+	in := "x := 1\n```\nx := 1\nx := 1\n```\nx := 1\n```\nx := 1\nx := 1\n```\n"
+	want := "x := 1\n```\nx := 1\nx := 1\n```\n```\nx := 1\nx := 1\n```\n"
+
+	got, err := DuplicateLinesWithOptions(in, DedupeOptions{Regions: []Region{MarkdownFence}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("call DuplicateLinesWithOptions(%#v)\n\texp: %#v\n\n\tgot: %#v", in, want, got)
+	}
+}
+
+func Test_DuplicateParagraphsWithOptions_haveUnicodeSpaceLines_ClassDecidesBlank(t *testing.T) {
+	in := "a\n\u00A0\nb\n\u00A0\na\n"
+	tests := []struct {
+		class WhitespaceClass
+		want  string
+	}{
+		{ASCIISpace, in},
+		{UnicodeSpace, "a\n\u00A0\nb\n"},
+	}
+	for _, test := range tests {
+		got, err := DuplicateParagraphsWithOptions(in, DedupeOptions{Class: test.class})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.want {
+			t.Errorf("call DuplicateParagraphsWithOptions(%#v), class %v\n\texp: %#v\n\n\tgot: %#v",
+				in, test.class, test.want, got)
+		}
+	}
+}
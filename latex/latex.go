@@ -0,0 +1,256 @@
+// Package latex splits LaTeX source into tokens, so that text can be
+// rewritten without touching escaped characters, comments, math or
+// verbatim content.
+package latex
+
+import "strings"
+
+// Kind is the kind of a token.
+type Kind int
+
+const (
+	Text          Kind = iota // characters without special meaning
+	Space                     // run of spaces, tabs and form feeds
+	Newline                   // single line break: \n, \r\n or \r
+	ControlWord               // backslash followed by letters: \emph
+	ControlSymbol             // backslash followed by one other character: \\, \{, \%
+	BeginGroup                // {
+	EndGroup                  // }
+	BeginOptional             // [
+	EndOptional               // ]
+	Comment                   // % up to, but not including, the line break
+	MathShift                 // $ or $$
+	Verbatim                  // verbatim environment or \verb, including its delimiters
+)
+
+var kindNames = [...]string{
+	Text:          "Text",
+	Space:         "Space",
+	Newline:       "Newline",
+	ControlWord:   "ControlWord",
+	ControlSymbol: "ControlSymbol",
+	BeginGroup:    "BeginGroup",
+	EndGroup:      "EndGroup",
+	BeginOptional: "BeginOptional",
+	EndOptional:   "EndOptional",
+	Comment:       "Comment",
+	MathShift:     "MathShift",
+	Verbatim:      "Verbatim",
+}
+
+func (k Kind) String() string {
+	if k < 0 || int(k) >= len(kindNames) {
+		return "Kind(?)"
+	}
+	return kindNames[k]
+}
+
+// Token is a piece of LaTeX source. Concatenating the Text of all tokens of
+// a source gives back the source.
+type Token struct {
+	Kind Kind
+	Text string
+	Pos  int  // byte offset of Text in the source
+	Math bool // token is inside inline or display math
+}
+
+// Is reports whether t is a token of kind k with text text.
+func (t Token) Is(k Kind, text string) bool {
+	return t.Kind == k && t.Text == text
+}
+
+// IsSpace reports whether t is a Space or Newline token.
+func (t Token) IsSpace() bool {
+	return t.Kind == Space || t.Kind == Newline
+}
+
+// VerbatimEnvironments are the environments whose content is returned as a
+// single Verbatim token.
+var VerbatimEnvironments = []string{
+	"verbatim", "verbatim*", "Verbatim", "BVerbatim", "LVerbatim",
+	"lstlisting", "minted", "comment",
+}
+
+// Tokenize splits s into tokens.
+func Tokenize(s string) []Token {
+	l := lexer{src: s}
+	for l.pos < len(s) {
+		l.next()
+	}
+	return l.tokens
+}
+
+type lexer struct {
+	src    string
+	pos    int
+	tokens []Token
+	math   string // delimiter that closes the current math mode, or ""
+}
+
+func (l *lexer) emit(k Kind, end int) {
+	l.tokens = append(l.tokens, Token{
+		Kind: k,
+		Text: l.src[l.pos:end],
+		Pos:  l.pos,
+		Math: l.math != "" && k != MathShift,
+	})
+	l.pos = end
+}
+
+func (l *lexer) next() {
+	s, i := l.src, l.pos
+	switch c := s[i]; {
+	case c == '\\':
+		l.escape()
+	case c == '{':
+		l.emit(BeginGroup, i+1)
+	case c == '}':
+		l.emit(EndGroup, i+1)
+	case c == '[':
+		l.emit(BeginOptional, i+1)
+	case c == ']':
+		l.emit(EndOptional, i+1)
+	case c == '%':
+		l.emit(Comment, i+lineLength(s[i:]))
+	case c == '$':
+		delim := "$"
+		if strings.HasPrefix(s[i:], "$$") {
+			delim = "$$"
+		}
+		l.shift(delim, delim)
+		l.emit(MathShift, i+len(delim))
+	case c == '\n':
+		l.emit(Newline, i+1)
+	case c == '\r':
+		if strings.HasPrefix(s[i:], "\r\n") {
+			l.emit(Newline, i+2)
+		} else {
+			l.emit(Newline, i+1)
+		}
+	case isSpace(c):
+		j := i + 1
+		for j < len(s) && isSpace(s[j]) {
+			j++
+		}
+		l.emit(Space, j)
+	default:
+		j := i + 1
+		for j < len(s) && !isSpecial(s[j]) {
+			j++
+		}
+		l.emit(Text, j)
+	}
+}
+
+// escape lexes the control sequence at l.pos.
+func (l *lexer) escape() {
+	s, i := l.src, l.pos
+	if i+1 == len(s) {
+		l.emit(Text, i+1)
+		return
+	}
+	if !isLetter(s[i+1]) {
+		// Control symbols are a single, possibly multibyte, character.
+		j := i + 2
+		for j < len(s) && s[j]&0xC0 == 0x80 {
+			j++
+		}
+		switch s[i+1] {
+		case '(':
+			l.emit(ControlSymbol, j)
+			l.shift("", `\)`)
+		case '[':
+			l.emit(ControlSymbol, j)
+			l.shift("", `\]`)
+		case ')', ']':
+			l.shift(s[i:j], "")
+			l.emit(ControlSymbol, j)
+		default:
+			l.emit(ControlSymbol, j)
+		}
+		return
+	}
+
+	j := i + 1
+	for j < len(s) && isLetter(s[j]) {
+		j++
+	}
+	switch s[i:j] {
+	case `\verb`:
+		if end := verbEnd(s, j); end > 0 {
+			l.emit(Verbatim, end)
+			return
+		}
+	case `\begin`:
+		if end := verbatimEnd(s, j); end > 0 {
+			l.emit(Verbatim, end)
+			return
+		}
+	}
+	l.emit(ControlWord, j)
+}
+
+// shift enters math mode closed by closing, or leaves math mode if the
+// current math mode is closed by delim.
+func (l *lexer) shift(delim, closing string) {
+	switch {
+	case l.math == "" && closing != "":
+		l.math = closing
+	case l.math != "" && l.math == delim:
+		l.math = ""
+	}
+}
+
+// verbEnd returns the end of the \verb argument starting at i, or 0.
+func verbEnd(s string, i int) int {
+	if i < len(s) && s[i] == '*' {
+		i++
+	}
+	if i >= len(s) || isLetter(s[i]) || isSpace(s[i]) || s[i] == '\n' || s[i] == '\r' {
+		return 0
+	}
+	delim := s[i]
+	line := s[i+1 : i+1+lineLength(s[i+1:])]
+	if j := strings.IndexByte(line, delim); j >= 0 {
+		return i + 1 + j + 1
+	}
+	return 0
+}
+
+// verbatimEnd returns the end of the verbatim environment whose \begin ends
+// at i, or 0 if the environment is not verbatim. An unterminated
+// environment ends with s.
+func verbatimEnd(s string, i int) int {
+	for _, name := range VerbatimEnvironments {
+		if !strings.HasPrefix(s[i:], "{"+name+"}") {
+			continue
+		}
+		end := `\end{` + name + `}`
+		if j := strings.Index(s[i:], end); j >= 0 {
+			return i + j + len(end)
+		}
+		return len(s)
+	}
+	return 0
+}
+
+// lineLength returns the length of the first line of s without its line
+// break.
+func lineLength(s string) int {
+	if i := strings.IndexAny(s, "\r\n"); i >= 0 {
+		return i
+	}
+	return len(s)
+}
+
+func isLetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\f'
+}
+
+func isSpecial(c byte) bool {
+	return strings.IndexByte("\\{}[]%$\n\r \t\f", c) >= 0
+}
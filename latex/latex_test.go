@@ -0,0 +1,82 @@
+package latex
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_Tokenize_haveLatexSource_TokensAreFound(t *testing.T) {
+	tests := []struct {
+		In   string
+		Want []Token
+	}{
+		{
+			In: "\\emph{a\\{b} % c}\r\n",
+			Want: []Token{
+				{Kind: ControlWord, Text: `\emph`, Pos: 0},
+				{Kind: BeginGroup, Text: `{`, Pos: 5},
+				{Kind: Text, Text: `a`, Pos: 6},
+				{Kind: ControlSymbol, Text: `\{`, Pos: 7},
+				{Kind: Text, Text: `b`, Pos: 9},
+				{Kind: EndGroup, Text: `}`, Pos: 10},
+				{Kind: Space, Text: ` `, Pos: 11},
+				{Kind: Comment, Text: `% c}`, Pos: 12},
+				{Kind: Newline, Text: "\r\n", Pos: 16},
+			},
+		},
+		{
+			In: `$x^{2}$ \[a\]\\[1em]`,
+			Want: []Token{
+				{Kind: MathShift, Text: `$`, Pos: 0},
+				{Kind: Text, Text: `x^`, Pos: 1, Math: true},
+				{Kind: BeginGroup, Text: `{`, Pos: 3, Math: true},
+				{Kind: Text, Text: `2`, Pos: 4, Math: true},
+				{Kind: EndGroup, Text: `}`, Pos: 5, Math: true},
+				{Kind: MathShift, Text: `$`, Pos: 6},
+				{Kind: Space, Text: ` `, Pos: 7},
+				{Kind: ControlSymbol, Text: `\[`, Pos: 8},
+				{Kind: Text, Text: `a`, Pos: 10, Math: true},
+				{Kind: ControlSymbol, Text: `\]`, Pos: 11},
+				{Kind: ControlSymbol, Text: `\\`, Pos: 13},
+				{Kind: BeginOptional, Text: `[`, Pos: 15},
+				{Kind: Text, Text: `1em`, Pos: 16},
+				{Kind: EndOptional, Text: `]`, Pos: 19},
+			},
+		},
+		{
+			In: "\\verb|{ %|\\begin{verbatim}\n  {}\n\\end{verbatim}\\end",
+			Want: []Token{
+				{Kind: Verbatim, Text: `\verb|{ %|`, Pos: 0},
+				{Kind: Verbatim, Text: "\\begin{verbatim}\n  {}\n\\end{verbatim}", Pos: 10},
+				{Kind: ControlWord, Text: `\end`, Pos: 46},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		got := Tokenize(test.In)
+		if !reflect.DeepEqual(test.Want, got) {
+			t.Errorf("call Tokenize(%#v)\n\texp: %+v\n\n\tgot: %+v", test.In, test.Want, got)
+		}
+	}
+}
+
+func Test_Tokenize_haveAnySource_TokensJoinToSource(t *testing.T) {
+	tests := []string{
+		`\begin{lstlisting}unterminated {`,
+		`\verb`,
+		`trailing \`,
+		"\\\u00e9t\u00e9 \\begin{itemize}\\item[a] b\\end{itemize}\r",
+	}
+
+	for _, in := range tests {
+		var b strings.Builder
+		for _, tok := range Tokenize(in) {
+			b.WriteString(tok.Text)
+		}
+		if got := b.String(); got != in {
+			t.Errorf("joined tokens of %#v give %#v", in, got)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package strdel
+
+import "strings"
+
+// A LineEnding is a line break convention. The line-oriented functions of
+// this package recognize all of them and keep the line break of every line
+// as it is, so mixed line endings stay mixed.
+type LineEnding string
+
+// Line endings.
+const (
+	LF   LineEnding = "\n"
+	CRLF LineEnding = "\r\n"
+	CR   LineEnding = "\r"
+	LS   LineEnding = "\u2028" // Unicode line separator
+	PS   LineEnding = "\u2029" // Unicode paragraph separator
+)
+
+// NormalizeLineEndings replaces every line break in string s by style.
+// Example: "a\r\nb\rc" --> "a\nb\nc" for LF
+func NormalizeLineEndings(s string, style LineEnding) string {
+	var b strings.Builder
+	last := 0
+	for i := 0; i < len(s); {
+		start, end := lineBreak(s[i:], true)
+		if start < 0 {
+			break
+		}
+		start, end = i+start, i+end
+		if s[start:end] != string(style) {
+			if last == 0 {
+				b.Grow(len(s))
+			}
+			b.WriteString(s[last:start])
+			b.WriteString(string(style))
+			last = end
+		}
+		i = end
+	}
+	if last == 0 {
+		return s
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// DetectLineEnding returns the most frequent line ending of string s, and
+// LF if s has no line break. Ties go to the line ending that comes first.
+func DetectLineEnding(s string) LineEnding {
+	counts := map[LineEnding]int{}
+	best := LF
+	for i := 0; i < len(s); {
+		start, end := lineBreak(s[i:], true)
+		if start < 0 {
+			break
+		}
+		le := LineEnding(s[i+start : i+end])
+		counts[le]++
+		if counts[le] > counts[best] {
+			best = le
+		}
+		i += end
+	}
+	return best
+}
+
+// lineBreak returns the start and end of the first line break in s, or
+// -1, -1. A carriage return at the end of s may be the start of a CRLF, so
+// it is only a line break if atEOF.
+func lineBreak[T string | []byte](s T, atEOF bool) (int, int) {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\n':
+			return i, i + 1
+		case '\r':
+			switch {
+			case i+1 < len(s) && s[i+1] == '\n':
+				return i, i + 2
+			case i+1 < len(s) || atEOF:
+				return i, i + 1
+			}
+			return -1, -1
+		case 0xE2:
+			// LS and PS are E2 80 A8 and E2 80 A9 in UTF-8.
+			if i+2 < len(s) && s[i+1] == 0x80 && (s[i+2] == 0xA8 || s[i+2] == 0xA9) {
+				return i, i + 3
+			}
+		}
+	}
+	return -1, -1
+}
+
+// breakLen returns the length of the line break at the start of s, or 0.
+func breakLen[T string | []byte](s T) int {
+	if start, end := lineBreak(s, true); start == 0 {
+		return end
+	}
+	return 0
+}
+
+// trailingBreakLen returns the length of the line break at the end of s,
+// or 0.
+func trailingBreakLen[T string | []byte](s T) int {
+	n := len(s)
+	switch {
+	case n >= 2 && s[n-2] == '\r' && s[n-1] == '\n':
+		return 2
+	case n >= 1 && (s[n-1] == '\n' || s[n-1] == '\r'):
+		return 1
+	case n >= 3 && s[n-3] == 0xE2 && s[n-2] == 0x80 && (s[n-1] == 0xA8 || s[n-1] == 0xA9):
+		return 3
+	}
+	return 0
+}
+
+// countBreaks returns the number of line breaks in s.
+func countBreaks(s string) int {
+	n := 0
+	for {
+		_, end := lineBreak(s, true)
+		if end < 0 {
+			return n
+		}
+		n++
+		s = s[end:]
+	}
+}
+
+// nextLine splits the first line of s into its content and line break.
+func nextLine(s string) (content, br string) {
+	start, end := lineBreak(s, true)
+	if start < 0 {
+		return s, ""
+	}
+	return s[:start], s[start:end]
+}
+
+// mapLines returns s with the content of every line replaced by fn. The
+// line breaks are kept; last tells fn whether the line has none.
+func mapLines(s string, fn func(content string, last bool) string) string {
+	var b strings.Builder
+	changed := false
+	for i := 0; i < len(s); {
+		content, br := nextLine(s[i:])
+		out := fn(content, br == "")
+		if !changed && out != content {
+			changed = true
+			b.Grow(len(s))
+			b.WriteString(s[:i])
+		}
+		if changed {
+			b.WriteString(out)
+			b.WriteString(br)
+		}
+		i += len(content) + len(br)
+	}
+	if !changed {
+		return s
+	}
+	return b.String()
+}
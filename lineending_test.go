@@ -0,0 +1,103 @@
+package strdel
+
+import (
+	"bytes"
+	"testing"
+)
+
+// mixed has every line ending.
+const mixed = "  1. one  \r\n\t2. two \n 3. three\t\r4. four \u2028 \u2029  5. five \r\n\r\n\n"
+
+func Test_LineOriented_haveMixedLineEndings_LineEndingsAreKept(t *testing.T) {
+	tidy := func(s string) string {
+		s, _ = Words(s, []string{"two", "four"}, Options{Tidy: true})
+		return s
+	}
+	tests := []struct {
+		name string
+		fn   func(string) string
+		want string
+	}{
+		{"TrailingSpaces", TrailingSpaces,
+			"  1. one\r\n\t2. two\n 3. three\r4. four\u2028\u2029  5. five\r\n\r\n\n"},
+		{"LeadingSpaces", LeadingSpaces,
+			"1. one  \r\n2. two \n3. three\t\r4. four \u2028\u20295. five \r\n\r\n\n"},
+		{"Numbering", Numbering,
+			"one  \r\ntwo \nthree\t\rfour \u2028 \u2029five \r\n\r\n\n"},
+		{"EmptyLine", EmptyLine,
+			"  1. one  \r\n\t2. two \n 3. three\t\r4. four \u2028  5. five "},
+		{"Words", tidy,
+			"  1. one  \r\n\t2.\n 3. three\t\r4.\u2028 \u2029  5. five \r\n\r\n\n"},
+		{"DuplicateLines", DuplicateLines, mixed},
+	}
+	for _, test := range tests {
+		if got := test.fn(mixed); got != test.want {
+			t.Errorf("call %s(%#v)\n\texp: %#v\n\n\tgot: %#v", test.name, mixed, test.want, got)
+		}
+	}
+}
+
+func Test_NormalizeLineEndings_haveMixedLineEndings_AllAreReplaced(t *testing.T) {
+	in := "a\r\nb\nc\rd\u2028e\u2029f\r"
+	tests := []struct {
+		style LineEnding
+		want  string
+	}{
+		{LF, "a\nb\nc\nd\ne\nf\n"},
+		{CRLF, "a\r\nb\r\nc\r\nd\r\ne\r\nf\r\n"},
+		{CR, "a\rb\rc\rd\re\rf\r"},
+		{LS, "a\u2028b\u2028c\u2028d\u2028e\u2028f\u2028"},
+		{PS, "a\u2029b\u2029c\u2029d\u2029e\u2029f\u2029"},
+	}
+	for _, test := range tests {
+		if got := NormalizeLineEndings(in, test.style); got != test.want {
+			t.Errorf("call NormalizeLineEndings(%#v, %#v)\n\texp: %#v\n\n\tgot: %#v", in, test.style, test.want, got)
+		}
+	}
+	if got := NormalizeLineEndings("a\nb", LF); got != "a\nb" {
+		t.Errorf("want text unchanged, got %#v", got)
+	}
+}
+
+func Test_DetectLineEnding_haveText_MostFrequentIsReturned(t *testing.T) {
+	tests := []struct {
+		in   string
+		want LineEnding
+	}{
+		{"", LF},
+		{"no line break", LF},
+		{"a\r\nb\r\nc\n", CRLF},
+		{"a\rb\rc\r\n", CR},
+		{"a\u2029b\n", PS},
+	}
+	for _, test := range tests {
+		if got := DetectLineEnding(test.in); got != test.want {
+			t.Errorf("call DetectLineEnding(%#v)\n\texp: %#v\n\n\tgot: %#v", test.in, test.want, got)
+		}
+	}
+}
+
+func Test_Writer_haveSplitLineBreaks_LinesAreFound(t *testing.T) {
+	in := "a \r\nb \rc \u2028d \u2029e \r"
+	want := TrailingSpaces(in)
+
+	// Write every byte on its own, splitting CRLF, LS and PS.
+	var out bytes.Buffer
+	w := NewWriter(&out, func(line string) string {
+		if _, end := lineBreak(line, true); end >= 0 && end < len(line) {
+			t.Errorf("step got several lines: %#v", line)
+		}
+		return TrailingSpacesStep(line)
+	})
+	for i := 0; i < len(in); i++ {
+		if _, err := w.Write([]byte{in[i]}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); got != want {
+		t.Errorf("call Writer(%#v)\n\texp: %#v\n\n\tgot: %#v", in, want, got)
+	}
+}
@@ -0,0 +1,309 @@
+package strdel
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// ListMarkers is a set of kinds of list markers.
+type ListMarkers uint
+
+// Kinds of list markers removed by NumberingWithOptions.
+const (
+	Arabic        ListMarkers = 1 << iota // 1. 12.
+	Roman                                 // iv. XII.
+	Alpha                                 // a. B.
+	Hierarchical                          // 1.2 1.2.3.
+	Parenthesized                         // 1) (1) a) (iv), with the kinds above
+	Bullets                               // - * + • ‣ ◦ ▪ ⁃
+
+	AllMarkers = Arabic | Roman | Alpha | Hierarchical | Parenthesized | Bullets
+
+	// DefaultMarkers leaves out the kinds that often start a line of
+	// prose: Roman and Alpha markers look like the initials in "A. Smith"
+	// or "I. e.", and Hierarchical ones like the decimal in "3.5 liters".
+	DefaultMarkers = Arabic | Parenthesized | Bullets
+)
+
+// bullets are the characters of bullet markers.
+const bullets = "-*+•‣◦▪⁃"
+
+// NumberingOptions control NumberingWithOptions.
+type NumberingOptions struct {
+	// Markers selects the kinds of markers to remove. Zero selects
+	// DefaultMarkers.
+	Markers ListMarkers
+
+	// Sequence removes a marker only if the marker of the previous or
+	// next list item continues it, like 1. and 2., a) and b), 1.1 and
+	// 1.2, or two bullets of the same kind. List items may be separated
+	// by blank lines. This keeps sentences like "2015. A year ..." that
+	// just look like list items.
+	Sequence bool
+}
+
+// NumberingWithOptions removes list markers at the begin of a line from
+// string s, together with the indentation before and the spaces after
+// them. Markers must be followed by a space or tab.
+// Example: (iv) Heading --> Heading
+func NumberingWithOptions(s string, opts NumberingOptions) string {
+	if opts.Markers == 0 {
+		opts.Markers = DefaultMarkers
+	}
+
+	type item struct {
+		start  int // offset of the line in s
+		marker listMarker
+		strip  bool
+	}
+	var items []item
+	prev := -1 // index of the previous item if only blank lines are between
+	for i := 0; i < len(s); {
+		content, br := nextLine(s[i:])
+		m, ok := parseListMarker(content, opts.Markers)
+		switch {
+		case ok:
+			it := item{start: i, marker: m, strip: !opts.Sequence}
+			if opts.Sequence && prev >= 0 && items[prev].marker.continuedBy(m) {
+				items[prev].strip, it.strip = true, true
+			}
+			items = append(items, it)
+			prev = len(items) - 1
+		case !blankLine(content, ASCIISpace):
+			prev = -1
+		}
+		i += len(content) + len(br)
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, it := range items {
+		if !it.strip {
+			continue
+		}
+		if last == 0 {
+			b.Grow(len(s))
+		}
+		b.WriteString(s[last:it.start])
+		last = it.start + it.marker.size
+	}
+	if last == 0 {
+		return s
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// A listMarker is a list marker at the start of a line.
+type listMarker struct {
+	size  int    // bytes of indentation, marker and spaces after it
+	style string // bullet, or delimiters and case like "(a)" for "(c)"
+	nums  []listNumber
+}
+
+// A listNumber is a reading of a numbered list marker. Letters that are
+// both a roman numeral and a letter of the alphabet have two readings.
+type listNumber struct {
+	kind   ListMarkers // Arabic, also for Hierarchical, Roman or Alpha
+	levels []int
+}
+
+// continuedBy reports whether next can be the marker of the list item
+// after the one of m.
+func (m listMarker) continuedBy(next listMarker) bool {
+	if m.style != next.style {
+		return false
+	}
+	if m.nums == nil {
+		return true
+	}
+	for _, a := range m.nums {
+		for _, b := range next.nums {
+			if a.kind == b.kind && nextNumber(a.levels, b.levels) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// nextNumber reports whether b can follow a in a hierarchical numbering:
+// 1.2 is followed by 1.3, by 1.2.1 or by 2.
+func nextNumber(a, b []int) bool {
+	n := len(b)
+	switch {
+	case n == len(a)+1:
+		return equalInts(a, b[:n-1]) && b[n-1] == 1
+	case n <= len(a):
+		return equalInts(a[:n-1], b[:n-1]) && b[n-1] == a[n-1]+1
+	}
+	return false
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// parseListMarker returns the marker of the kinds in markers at the start
+// of line.
+func parseListMarker(line string, markers ListMarkers) (listMarker, bool) {
+	i := 0
+	for i < len(line) && (line[i] == ' ' || line[i] == '\t') {
+		i++
+	}
+
+	var m listMarker
+	r, n := utf8.DecodeRuneInString(line[i:])
+	if markers&Bullets != 0 && r != utf8.RuneError && strings.ContainsRune(bullets, r) {
+		m.style = line[i : i+n]
+		i += n
+	} else {
+		var ok bool
+		if m.style, m.nums, i, ok = parseNumber(line, i, markers); !ok {
+			return listMarker{}, false
+		}
+	}
+
+	if i == len(line) || line[i] != ' ' && line[i] != '\t' {
+		return listMarker{}, false
+	}
+	for i < len(line) && (line[i] == ' ' || line[i] == '\t') {
+		i++
+	}
+	m.size = i
+	return m, true
+}
+
+// parseNumber parses the numbered list marker at line[i:] and returns its
+// style, its readings and its end.
+func parseNumber(line string, i int, markers ListMarkers) (string, []listNumber, int, bool) {
+	var buf [3]byte
+	style := buf[:0]
+	open := i < len(line) && line[i] == '('
+	if open {
+		if markers&Parenthesized == 0 {
+			return "", nil, 0, false
+		}
+		style = append(style, '(')
+		i++
+	}
+
+	var nums []listNumber
+	start := i
+	switch {
+	case i < len(line) && isDigit(line[i]):
+		var levels []int
+		for {
+			n, end := 0, i
+			for end < len(line) && isDigit(line[end]) && end-i < 9 {
+				n = 10*n + int(line[end]-'0')
+				end++
+			}
+			levels = append(levels, n)
+			i = end
+			if i+1 < len(line) && line[i] == '.' && isDigit(line[i+1]) {
+				i++
+				continue
+			}
+			break
+		}
+		if len(levels) == 1 && markers&Arabic != 0 || len(levels) > 1 && markers&Hierarchical != 0 {
+			nums = append(nums, listNumber{Arabic, levels})
+		}
+		style = append(style, '1')
+
+	case i < len(line) && isASCIILetter(line[i]):
+		for i < len(line) && isASCIILetter(line[i]) {
+			i++
+		}
+		letters := line[start:i]
+		if len(letters) == 1 && markers&Alpha != 0 {
+			nums = append(nums, listNumber{Alpha, []int{int(letters[0]|0x20) - 'a' + 1}})
+		}
+		if n, ok := romanValue(letters); ok && markers&Roman != 0 {
+			nums = append(nums, listNumber{Roman, []int{n}})
+		}
+		if letters[0] < 'a' {
+			style = append(style, 'A')
+		} else {
+			style = append(style, 'a')
+		}
+	}
+	if len(nums) == 0 {
+		return "", nil, 0, false
+	}
+
+	switch {
+	case i < len(line) && line[i] == ')':
+		if markers&Parenthesized == 0 {
+			return "", nil, 0, false
+		}
+	case open:
+		return "", nil, 0, false
+	case i < len(line) && line[i] == '.':
+	case len(nums[0].levels) > 1:
+		// Hierarchical numbers need no final dot.
+		return string(append(style, '.')), nums, i, true
+	default:
+		return "", nil, 0, false
+	}
+	style = append(style, line[i])
+	return string(style), nums, i + 1, true
+}
+
+// romanValue returns the value of a roman numeral written in a single
+// case, like "xiv" or "XIV".
+func romanValue(s string) (int, bool) {
+	if !romanNumeral.MatchString(s) || strings.ToLower(s) != s && strings.ToUpper(s) != s {
+		return 0, false
+	}
+	n := 0
+	for i := 0; i < len(s); i++ {
+		v := romanDigit(s[i])
+		if i+1 < len(s) && v < romanDigit(s[i+1]) {
+			n -= v
+		} else {
+			n += v
+		}
+	}
+	return n, true
+}
+
+var romanNumeral = regexp.MustCompile(`(?i)^M{0,3}(?:CM|CD|D?C{0,3})(?:XC|XL|L?X{0,3})(?:IX|IV|V?I{0,3})$`)
+
+func romanDigit(c byte) int {
+	switch c | 0x20 {
+	case 'i':
+		return 1
+	case 'v':
+		return 5
+	case 'x':
+		return 10
+	case 'l':
+		return 50
+	case 'c':
+		return 100
+	case 'd':
+		return 500
+	case 'm':
+		return 1000
+	}
+	return 0
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isASCIILetter(c byte) bool {
+	return 'a' <= c|0x20 && c|0x20 <= 'z'
+}
@@ -0,0 +1,51 @@
+package strdel
+
+import "testing"
+
+func Test_NumberingWithOptions_haveListMarkers_MarkersAreRemoved(t *testing.T) {
+	tests := []struct {
+		in, want string
+		markers  ListMarkers
+	}{
+		{in: "1. a\n  12.\tb\n2015. c\n", want: "a\nb\nc\n", markers: Arabic},
+		{in: "iv. a\nXII. b\nic. c\nIi. d\n", want: "a\nb\nic. c\nIi. d\n", markers: Roman},
+		{in: "a. x\nB. y\nab. z\n", want: "x\ny\nab. z\n", markers: Alpha},
+		{in: "1.2 a\n1.2.3. b\n1. c\n3.5liters\n", want: "a\nb\n1. c\n3.5liters\n", markers: Hierarchical},
+		{in: "1) a\n(2) b\n(c) d\n(iv) e\n(5 f\n", want: "1) a\n(2) b\n(c) d\n(iv) e\n(5 f\n", markers: Arabic | Alpha | Roman},
+		{in: "1) a\n(2) b\n(c) d\n(iv) e\n(5 f\n", want: "a\nb\nd\ne\n(5 f\n", markers: AllMarkers},
+		{in: "- a\n* b\n• c\n‣ d\n--- e\n-f\n", want: "a\nb\nc\nd\n--- e\n-f\n", markers: Bullets},
+		{in: "e.g. this\n1.\n\r\n1. Heading\r\n", want: "e.g. this\n1.\n\r\nHeading\r\n"},
+		{in: "3.5 liters of water\n3.5\tkg\n- 2.5 cups\n", want: "3.5 liters of water\n3.5\tkg\n2.5 cups\n"},
+		{in: "A. Smith wrote\nI. e. this\nJ. R. R. Tolkien\n(1) x\n(a) y\n", want: "A. Smith wrote\nI. e. this\nJ. R. R. Tolkien\nx\n(a) y\n"},
+	}
+	for _, test := range tests {
+		got := NumberingWithOptions(test.in, NumberingOptions{Markers: test.markers})
+		if got != test.want {
+			t.Errorf("call NumberingWithOptions(%#v), markers %b\n\texp: %#v\n\n\tgot: %#v",
+				test.in, test.markers, test.want, got)
+		}
+	}
+}
+
+func Test_NumberingWithOptions_haveSequence_OnlyListsAreRemoved(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"2015. A year of change.\nIt started in spring.\n", "2015. A year of change.\nIt started in spring.\n"},
+		{"1. a\n2. b\n\n3. c\n", "a\nb\n\nc\n"},
+		{"1. a\ntext\n2. b\n", "1. a\ntext\n2. b\n"},
+		{"1. a\n3. b\n", "1. a\n3. b\n"},
+		{"1. a\n2) b\n", "1. a\n2) b\n"},
+		{"1. Intro\n1.1 Scope\n1.2 Terms\n2. Body\n", "Intro\nScope\nTerms\nBody\n"},
+		{"h. x\ni. y\n", "x\ny\n"},
+		{"i. x\nii. y\niii. z\n", "x\ny\nz\n"},
+		{"(a) x\n(b) y\n", "x\ny\n"},
+		{"- x\n- y\n* z\n", "x\ny\n* z\n"},
+	}
+	for _, test := range tests {
+		got := NumberingWithOptions(test.in, NumberingOptions{Markers: AllMarkers, Sequence: true})
+		if got != test.want {
+			t.Errorf("call NumberingWithOptions(%#v), sequence\n\texp: %#v\n\n\tgot: %#v", test.in, test.want, got)
+		}
+	}
+}
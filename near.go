@@ -0,0 +1,154 @@
+package strdel
+
+import (
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// NearOptions configures NearDuplicates. The zero value uses the defaults.
+type NearOptions struct {
+	// Threshold is the estimated Jaccard similarity of the shingles of two
+	// items at or above which the later one is removed. Default 0.8.
+	Threshold float64
+	// Shingle is the number of consecutive words per shingle. Default 3.
+	Shingle int
+	// Hashes is the number of MinHash functions; more hashes give better
+	// estimates. Default 128.
+	Hashes int
+}
+
+const (
+	defaultNearThreshold = 0.8
+	defaultNearShingle   = 3
+	defaultNearHashes    = 128
+)
+
+// A Cluster lists the items NearDuplicates removed as near duplicates of a
+// kept item. Indices refer to the input.
+type Cluster struct {
+	Kept    int
+	Removed []int
+}
+
+// NearDuplicates deletes the items of a slice that are nearly the same as
+// an earlier kept item, keeping the first item of every cluster in order.
+// Items are compared case-insensitively by their words, so differences in
+// whitespace and punctuation are ignored. All numbers count as the same
+// word, so items that differ only in a date or a count are near
+// duplicates. The words are split into
+// shingles of opts.Shingle words whose Jaccard similarity is estimated by
+// MinHash. The clusters of removed items are returned in the order of
+// their kept items; kept items without near duplicates have no cluster.
+//
+// Every item is compared with all kept items, so the cost grows with the
+// product of the number of items and the number of kept items.
+func NearDuplicates(items []string, opts NearOptions) ([]string, []Cluster) {
+	if opts.Threshold <= 0 {
+		opts.Threshold = defaultNearThreshold
+	}
+	if opts.Shingle <= 0 {
+		opts.Shingle = defaultNearShingle
+	}
+	if opts.Hashes <= 0 {
+		opts.Hashes = defaultNearHashes
+	}
+
+	kept := []string{}
+	var signatures [][]uint64
+	var clusters []Cluster // aligned with kept
+
+	for i, item := range items {
+		sig := minHash(shingles(item, opts.Shingle), opts.Hashes)
+		j := mostSimilar(sig, signatures, opts.Threshold)
+		if j >= 0 {
+			clusters[j].Removed = append(clusters[j].Removed, i)
+			continue
+		}
+		kept = append(kept, item)
+		signatures = append(signatures, sig)
+		clusters = append(clusters, Cluster{Kept: i})
+	}
+
+	removed := []Cluster{}
+	for _, c := range clusters {
+		if len(c.Removed) > 0 {
+			removed = append(removed, c)
+		}
+	}
+	return kept, removed
+}
+
+// mostSimilar returns the index of the signature most similar to sig, or
+// -1 if none reaches threshold.
+func mostSimilar(sig []uint64, signatures [][]uint64, threshold float64) int {
+	best, bestSim := -1, 0.0
+	for j, other := range signatures {
+		same := 0
+		for k := range sig {
+			if sig[k] == other[k] {
+				same++
+			}
+		}
+		if sim := float64(same) / float64(len(sig)); sim >= threshold && sim > bestSim {
+			best, bestSim = j, sim
+		}
+	}
+	return best
+}
+
+// shingles returns the hashes of all runs of size lower-cased words of s,
+// where every number is replaced by the same word. Texts with fewer words
+// form a single shingle.
+func shingles(s string, size int) []uint64 {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, w := range words {
+		if strings.IndexFunc(w, func(r rune) bool { return !unicode.IsDigit(r) }) < 0 {
+			words[i] = "0"
+		}
+	}
+	if len(words) < size {
+		size = len(words)
+	}
+
+	var hashes []uint64
+	for i := 0; i+size <= len(words); i++ {
+		h := fnv.New64a()
+		for _, w := range words[i : i+size] {
+			h.Write([]byte(w))
+			h.Write([]byte{0})
+		}
+		hashes = append(hashes, h.Sum64())
+		if size == 0 {
+			break
+		}
+	}
+	return hashes
+}
+
+// minHash returns the MinHash signature of a set of shingle hashes. The
+// n hash functions are the shingle hash mixed with a per-function seed.
+func minHash(shingles []uint64, n int) []uint64 {
+	sig := make([]uint64, n)
+	for i := range sig {
+		sig[i] = math.MaxUint64
+	}
+	for _, s := range shingles {
+		for i := range sig {
+			if h := mix64(s ^ uint64(i+1)*0x9e3779b97f4a7c15); h < sig[i] {
+				sig[i] = h
+			}
+		}
+	}
+	return sig
+}
+
+// mix64 is the splitmix64 finalizer.
+func mix64(x uint64) uint64 {
+	x = (x ^ x>>30) * 0xbf58476d1ce4e5b9
+	x = (x ^ x>>27) * 0x94d049bb133111eb
+	return x ^ x>>31
+}
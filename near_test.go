@@ -0,0 +1,52 @@
+package strdel
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_NearDuplicates_haveScrapedParagraphs_VariantsAreClustered(t *testing.T) {
+	in := []string{
+		"The city council met on Monday to discuss the new budget for parks and public libraries.",
+		"Weather: sunny with a light breeze from the west.",
+		"The  city council met on Monday, to discuss the new budget for parks and public libraries!",
+		"THE CITY COUNCIL MET ON MONDAY TO DISCUSS THE NEW BUDGET FOR PARKS AND PUBLIC LIBRARIES",
+		"Published 2024-03-01. Share this article with your friends and family today, and subscribe to our newsletter for more local news every morning.",
+		"Published 2024-03-02. Share this article with your friends and family today, and subscribe to our newsletter for more local news every morning.",
+		"A completely different paragraph about football results and transfers.",
+		"The museum opens its new exhibition on 2024-05-12 for all visitors.",
+		"The museum opens its new exhibition on 2025-06-03 for all visitors.",
+	}
+
+	kept, clusters := NearDuplicates(in, NearOptions{})
+
+	if want := []string{in[0], in[1], in[4], in[6], in[7]}; !reflect.DeepEqual(want, kept) {
+		t.Errorf("kept\n\texp: %#v\n\n\tgot: %#v", want, kept)
+	}
+	want := []Cluster{{Kept: 0, Removed: []int{2, 3}}, {Kept: 4, Removed: []int{5}}, {Kept: 7, Removed: []int{8}}}
+	if !reflect.DeepEqual(want, clusters) {
+		t.Errorf("clusters\n\texp: %+v\n\n\tgot: %+v", want, clusters)
+	}
+}
+
+func Test_NearDuplicates_haveThreshold_DissimilarItemsAreKept(t *testing.T) {
+	in := []string{
+		"one two three four five six seven eight",
+		"one two three four nine ten eleven twelve",
+		"",
+		" ... ",
+	}
+
+	kept, clusters := NearDuplicates(in, NearOptions{})
+	if want := []string{in[0], in[1], in[2]}; !reflect.DeepEqual(want, kept) {
+		t.Errorf("kept\n\texp: %#v\n\n\tgot: %#v", want, kept)
+	}
+	if want := []Cluster{{Kept: 2, Removed: []int{3}}}; !reflect.DeepEqual(want, clusters) {
+		t.Errorf("clusters\n\texp: %+v\n\n\tgot: %+v", want, clusters)
+	}
+
+	kept, clusters = NearDuplicates(nil, NearOptions{})
+	if kept == nil || len(kept) != 0 || len(clusters) != 0 {
+		t.Errorf("want empty result for nil input, got %#v, %#v", kept, clusters)
+	}
+}
@@ -0,0 +1,5 @@
+//go:build !race
+
+package strdel
+
+const raceEnabled = false
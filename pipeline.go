@@ -0,0 +1,302 @@
+package strdel
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// builtins are the cleanup functions that can be added to a Pipeline by
+// name.
+var builtins = map[string]func(string) string{
+	"Dedent":                     Dedent,
+	"DuplicateLines":             DuplicateLines,
+	"DuplicateParagraphs":        DuplicateParagraphs,
+	"EmptyBrackets":              EmptyBrackets,
+	"EmptyLine":                  EmptyLine,
+	"EmptyLinesInMacros":         EmptyLinesInMacros,
+	"EmptyMacros":                EmptyNestedMacros,
+	"InternalSpaces":             InternalSpaces,
+	"LeadingSpaces":              LeadingSpaces,
+	"NormalizeSpaces":            NormalizeSpaces,
+	"Numbering":                  Numbering,
+	"SpaceAfterOpeningBrackets":  SpaceAfterOpeningBrackets,
+	"SpaceBeforeClosingBrackets": SpaceBeforeClosingBrackets,
+	"TrailingSpaces":             TrailingSpaces,
+}
+
+// Builtins returns the names of the functions that AddBuiltin accepts.
+func Builtins() []string {
+	names := make([]string, 0, len(builtins))
+	for name := range builtins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// StepStats reports what a pipeline step did over all runs of its pipeline.
+type StepStats struct {
+	Name      string
+	Enabled   bool
+	Runs      int           // number of times the step ran
+	Changes   int           // runs in which the step changed the text
+	Deletions int           // matches deleted by word and regexp steps
+	Duration  time.Duration // total time spent in the step
+}
+
+// A Pipeline runs named cleanup steps in the order they were added. It is
+// safe for concurrent use.
+type Pipeline struct {
+	mu      sync.Mutex
+	steps   []*step
+	regions []Region
+}
+
+type step struct {
+	run   func(string) (string, int, error)
+	user  bool // added with Add or AddFunc
+	stats StepStats
+}
+
+// NewPipeline returns an empty pipeline.
+func NewPipeline() *Pipeline {
+	return &Pipeline{}
+}
+
+// Add appends the step fn under name.
+func (p *Pipeline) Add(name string, fn func(string) string) error {
+	return p.addUser(name, func(s string) (string, int, error) {
+		return fn(s), 0, nil
+	})
+}
+
+// AddFunc appends the step fn under name. An error returned by fn stops
+// the run of the pipeline.
+func (p *Pipeline) AddFunc(name string, fn func(string) (string, error)) error {
+	return p.addUser(name, func(s string) (string, int, error) {
+		s, err := fn(s)
+		return s, 0, err
+	})
+}
+
+// AddBuiltin appends the strdel function called name, see Builtins.
+func (p *Pipeline) AddBuiltin(name string) error {
+	run, err := builtinStep(name)
+	if err != nil {
+		return err
+	}
+	return p.add(name, run)
+}
+
+// AddWords appends a step under name that deletes words as Words does. The
+// words are checked and compiled once, when the step is added.
+func (p *Pipeline) AddWords(name string, words []string, opts Options) error {
+	run, err := wordsStep(words, opts)
+	if err != nil {
+		return err
+	}
+	return p.add(name, run)
+}
+
+// AddRegExp appends a step under name that deletes regExp as
+// RegExpWithOptions does.
+func (p *Pipeline) AddRegExp(name string, regExp string, opts Options) error {
+	run, err := regExpStep(regExp, opts)
+	if err != nil {
+		return err
+	}
+	return p.add(name, run)
+}
+
+func builtinStep(name string) (func(string) (string, int, error), error) {
+	fn, ok := builtins[name]
+	if !ok {
+		return nil, fmt.Errorf("strdel: unknown builtin %q", name)
+	}
+	return func(s string) (string, int, error) {
+		return fn(s), 0, nil
+	}, nil
+}
+
+func wordsStep(words []string, opts Options) (func(string) (string, int, error), error) {
+	m, err := newWordMatcher(words, opts)
+	if err != nil {
+		return nil, err
+	}
+	return func(s string) (string, int, error) {
+		spans := m.find(s)
+		return deleteSpans(s, spans, opts.Tidy), nonEmpty(spans), nil
+	}, nil
+}
+
+func regExpStep(regExp string, opts Options) (func(string) (string, int, error), error) {
+	if _, err := findRegExp("", regExp, opts); err != nil {
+		return nil, err
+	}
+	return func(s string) (string, int, error) {
+		spans, err := findRegExp(s, regExp, opts)
+		return deleteSpans(s, spans, opts.Tidy), nonEmpty(spans), err
+	}, nil
+}
+
+// nonEmpty returns the number of spans that delete something.
+func nonEmpty(spans []span) int {
+	n := 0
+	for _, sp := range spans {
+		if sp.start != sp.end {
+			n++
+		}
+	}
+	return n
+}
+
+func (p *Pipeline) add(name string, run func(string) (string, int, error)) error {
+	return p.addStep(name, &step{run: run})
+}
+
+// addUser is like add for steps whose function comes from the caller.
+func (p *Pipeline) addUser(name string, run func(string) (string, int, error)) error {
+	return p.addStep(name, &step{run: run, user: true})
+}
+
+func (p *Pipeline) addStep(name string, st *step) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.lookup(name) != nil {
+		return fmt.Errorf("strdel: duplicate pipeline step %q", name)
+	}
+	st.stats = StepStats{Name: name, Enabled: true}
+	p.steps = append(p.steps, st)
+	return nil
+}
+
+// lookup returns the step called name or nil. p.mu must be held.
+func (p *Pipeline) lookup(name string) *step {
+	for _, st := range p.steps {
+		if st.stats.Name == name {
+			return st
+		}
+	}
+	return nil
+}
+
+// Enable makes the step called name run again.
+func (p *Pipeline) Enable(name string) error {
+	return p.setEnabled(name, true)
+}
+
+// Disable skips the step called name in future runs.
+func (p *Pipeline) Disable(name string) error {
+	return p.setEnabled(name, false)
+}
+
+func (p *Pipeline) setEnabled(name string, enabled bool) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	st := p.lookup(name)
+	if st == nil {
+		return fmt.Errorf("strdel: unknown pipeline step %q", name)
+	}
+	st.stats.Enabled = enabled
+	return nil
+}
+
+// Protect makes all runs leave the spans of the text described by regions
+// intact, as Protect does. The regions are checked when they are set.
+func (p *Pipeline) Protect(regions ...Region) error {
+	if err := checkRegions(regions); err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.regions = append(p.regions, regions...)
+	return nil
+}
+
+// Run passes s through all enabled steps in order. It stops at the first
+// step that fails and returns the text as it was before that step. If a
+// step loses a protected region, Run returns s and ErrProtectedRegionLost.
+func (p *Pipeline) Run(s string) (string, error) {
+	steps, regions := p.enabled()
+	return p.runMasked(s, steps, regions)
+}
+
+// enabled returns the enabled steps and the protected regions of p.
+func (p *Pipeline) enabled() ([]*step, []Region) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var steps []*step
+	for _, st := range p.steps {
+		if st.stats.Enabled {
+			steps = append(steps, st)
+		}
+	}
+	return steps, p.regions
+}
+
+// hasUserSteps reports whether steps has a step added with Add or AddFunc.
+func hasUserSteps(steps []*step) bool {
+	for _, st := range steps {
+		if st.user {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Pipeline) runMasked(s string, steps []*step, regions []Region) (string, error) {
+	masked, saved, err := mask(s, regions)
+	if err != nil {
+		return s, err
+	}
+	out, err := p.run(masked, steps)
+	restored, uerr := unmask(out, saved)
+	if uerr != nil {
+		if err == nil {
+			err = uerr
+		}
+		return s, err
+	}
+	return restored, err
+}
+
+func (p *Pipeline) run(s string, steps []*step) (string, error) {
+	for _, st := range steps {
+		start := time.Now()
+		out, deletions, err := st.run(s)
+		elapsed := time.Since(start)
+
+		p.mu.Lock()
+		st.stats.Runs++
+		st.stats.Duration += elapsed
+		st.stats.Deletions += deletions
+		if err == nil && out != s {
+			st.stats.Changes++
+		}
+		p.mu.Unlock()
+
+		if err != nil {
+			return s, fmt.Errorf("strdel: pipeline step %q: %w", st.stats.Name, err)
+		}
+		s = out
+	}
+	return s, nil
+}
+
+// Stats returns the statistics of all steps in pipeline order.
+func (p *Pipeline) Stats() []StepStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := make([]StepStats, len(p.steps))
+	for i, st := range p.steps {
+		stats[i] = st.stats
+	}
+	return stats
+}
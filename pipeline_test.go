@@ -0,0 +1,103 @@
+package strdel
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func Test_Pipeline_haveSeveralSteps_StepsRunInOrder(t *testing.T) {
+	p := NewPipeline()
+	for _, name := range []string{"LeadingSpaces", "TrailingSpaces", "EmptyMacros"} {
+		if err := p.AddBuiltin(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := p.AddWords("boilerplate", []string{"Advertisement"}, Options{Tidy: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Add("upper", strings.ToUpper); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := p.Run("  Advertisement \\emph{} text  \n  more\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "TEXT\nMORE\n"; got != want {
+		t.Errorf("call Run\n\texp: %#v\n\n\tgot: %#v", want, got)
+	}
+
+	if err := p.Disable("upper"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := p.Run("text"); got != "text" {
+		t.Errorf("disabled step ran: %#v", got)
+	}
+
+	stats := p.Stats()
+	if len(stats) != 5 {
+		t.Fatalf("want 5 steps, got %d", len(stats))
+	}
+	want := []StepStats{
+		{Name: "LeadingSpaces", Enabled: true, Runs: 2, Changes: 1},
+		{Name: "TrailingSpaces", Enabled: true, Runs: 2, Changes: 1},
+		{Name: "EmptyMacros", Enabled: true, Runs: 2, Changes: 1},
+		{Name: "boilerplate", Enabled: true, Runs: 2, Changes: 1, Deletions: 1},
+		{Name: "upper", Enabled: false, Runs: 1, Changes: 1},
+	}
+	for i := range want {
+		stats[i].Duration = 0
+		if stats[i] != want[i] {
+			t.Errorf("stats of step %d\n\texp: %+v\n\tgot: %+v", i, want[i], stats[i])
+		}
+	}
+}
+
+func Test_Pipeline_haveInvalidSteps_ReturnErrors(t *testing.T) {
+	p := NewPipeline()
+	if err := p.AddBuiltin("NoSuchFunction"); err == nil {
+		t.Errorf("call AddBuiltin: want error for unknown builtin")
+	}
+	if err := p.AddRegExp("broken", `(`, Options{}); err == nil {
+		t.Errorf("call AddRegExp: want error for invalid pattern")
+	}
+	if err := p.AddBuiltin("EmptyLine"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddBuiltin("EmptyLine"); err == nil {
+		t.Errorf("call AddBuiltin: want error for duplicate step")
+	}
+	if err := p.Enable("missing"); err == nil {
+		t.Errorf("call Enable: want error for unknown step")
+	}
+
+	failure := errors.New("failure")
+	p.AddFunc("fail", func(s string) (string, error) { return "", failure })
+	got, err := p.Run("a\n\nb")
+	if !errors.Is(err, failure) || got != "a\nb" {
+		t.Errorf("call Run: want %#v and failure, got %#v, %v", "a\nb", got, err)
+	}
+}
+
+func Test_Pipeline_haveEmptyMatches_NoDeletionsAreCounted(t *testing.T) {
+	p := NewPipeline()
+	if err := p.AddRegExp("zs", `z*`, Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddRegExp("bs", `b*`, Options{}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := p.Run("abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "ac" {
+		t.Errorf("call Run\n\texp: %#v\n\n\tgot: %#v", "ac", got)
+	}
+	stats := p.Stats()
+	if stats[0].Deletions != 0 || stats[1].Deletions != 1 {
+		t.Errorf("want 0 and 1 deletions, got %d and %d", stats[0].Deletions, stats[1].Deletions)
+	}
+}
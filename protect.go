@@ -0,0 +1,329 @@
+package strdel
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/frankMilde/strdel/latex"
+)
+
+// Region describes spans of text that cleanup functions must leave intact.
+// Begin and End are regular expressions matching the delimiters, which
+// belong to the region. End may refer to submatches of Begin as in
+// regexp.Expand, e.g. ${1}; they are matched literally. A region whose end
+// is missing extends to the end of the text; an empty End ends the region
+// with the match of Begin.
+type Region struct {
+	Name  string
+	Begin string
+	End   string
+}
+
+// Built-in regions.
+var (
+	// LaTeXVerbatim protects the verbatim environments of the latex
+	// package, like verbatim and lstlisting.
+	LaTeXVerbatim = Region{
+		Name:  "latex-verbatim",
+		Begin: `\\begin\{(` + quoteAll(latex.VerbatimEnvironments) + `)\}`,
+		End:   `\\end\{${1}\}`,
+	}
+
+	// MarkdownFence protects fenced code blocks opened and closed by at
+	// least three backticks or tildes.
+	MarkdownFence = Region{
+		Name:  "markdown-fence",
+		Begin: "(?m)^[ \\t]*(```+|~~~+)[^\\n]*\\n",
+		End:   "(?m)^[ \\t]*${1}[ \\t]*$",
+	}
+
+	// MarkdownCodeSpan protects inline code spans delimited by one to
+	// three backticks. Like paragraphs, code spans end at a blank line,
+	// so an unmatched backtick does not protect the text after it. List
+	// MarkdownFence before it, so that fences are not taken for code
+	// spans.
+	MarkdownCodeSpan = Region{
+		Name: "markdown-code-span",
+		Begin: "```(?:[^`\\n]|`{1,2}[^`\\n]|" + codeSpanBreak + ")+?```|" +
+			"``(?:[^`\\n]|`[^`\\n]|" + codeSpanBreak + ")+?``|" +
+			"`(?:[^`\\n]|" + codeSpanBreak + ")+`",
+	}
+
+	// HTMLPre protects HTML <pre> elements.
+	HTMLPre = Region{
+		Name:  "html-pre",
+		Begin: `(?i)<pre\b[^>]*>`,
+		End:   `(?i)</pre\s*>`,
+	}
+)
+
+// codeSpanBreak matches a line break inside a code span, which must not be
+// followed by a blank line.
+const codeSpanBreak = "\\n[ \\t]*[^\\s`]"
+
+// Regions returns the built-in regions.
+func Regions() []Region {
+	return []Region{LaTeXVerbatim, MarkdownFence, MarkdownCodeSpan, HTMLPre}
+}
+
+// RegionNamed returns the built-in region called name.
+func RegionNamed(name string) (Region, bool) {
+	for _, r := range Regions() {
+		if r.Name == name {
+			return r, true
+		}
+	}
+	return Region{}, false
+}
+
+// RegionNames returns the names of the built-in regions.
+func RegionNames() []string {
+	var names []string
+	for _, r := range Regions() {
+		names = append(names, r.Name)
+	}
+	return names
+}
+
+func quoteAll(words []string) string {
+	quoted := make([]string, len(words))
+	for i, w := range words {
+		quoted[i] = regexp.QuoteMeta(w)
+	}
+	return strings.Join(quoted, "|")
+}
+
+// ErrProtectedRegionLost is returned if a function removed, duplicated or
+// reordered protected regions.
+var ErrProtectedRegionLost = errors.New("strdel: protected region lost")
+
+// Protect applies fn to s, leaving all spans of s described by regions
+// byte-for-byte intact. Each span is replaced by a placeholder made of
+// private use characters while fn runs, so fn sees it as a single word. If
+// fn loses a placeholder, Protect returns s and ErrProtectedRegionLost.
+func Protect(s string, fn func(string) string, regions ...Region) (string, error) {
+	masked, saved, err := mask(s, regions)
+	if err != nil {
+		return s, err
+	}
+	out, err := unmask(fn(masked), saved)
+	if err != nil {
+		return s, err
+	}
+	return out, nil
+}
+
+// Placeholders are built from the private use characters placeholderOpen,
+// the digits of the region index starting at placeholderZero, and
+// placeholderClose.
+const (
+	placeholderOpen  = '\uE000'
+	placeholderClose = '\uE001'
+	placeholderZero  = '\uE010'
+)
+
+// mask replaces the regions in s by placeholders and returns the replaced
+// text of each region.
+func mask(s string, regions []Region) (string, []string, error) {
+	if len(regions) == 0 {
+		return s, nil, nil
+	}
+	if strings.ContainsRune(s, placeholderOpen) || strings.ContainsRune(s, placeholderClose) {
+		return s, nil, errors.New("strdel: text contains protected region placeholder")
+	}
+	spans, err := findRegions(s, regions)
+	if err != nil || len(spans) == 0 {
+		return s, nil, err
+	}
+
+	var b strings.Builder
+	saved := make([]string, len(spans))
+	last := 0
+	for i, sp := range spans {
+		b.WriteString(s[last:sp.start])
+		b.WriteString(placeholder(i))
+		saved[i] = s[sp.start:sp.end]
+		last = sp.end
+	}
+	b.WriteString(s[last:])
+	return b.String(), saved, nil
+}
+
+func placeholder(i int) string {
+	digits := []rune{placeholderClose}
+	for {
+		digits = append(digits, placeholderZero+rune(i%10))
+		i /= 10
+		if i == 0 {
+			break
+		}
+	}
+	digits = append(digits, placeholderOpen)
+	for l, r := 0, len(digits)-1; l < r; l, r = l+1, r-1 {
+		digits[l], digits[r] = digits[r], digits[l]
+	}
+	return string(digits)
+}
+
+// unmask puts the saved regions back in place of their placeholders.
+func unmask(s string, saved []string) (string, error) {
+	if len(saved) == 0 {
+		return s, nil
+	}
+	var b strings.Builder
+	next := 0
+	for {
+		i := strings.IndexRune(s, placeholderOpen)
+		if i < 0 {
+			break
+		}
+		b.WriteString(s[:i])
+		if next >= len(saved) || !strings.HasPrefix(s[i:], placeholder(next)) {
+			return s, ErrProtectedRegionLost
+		}
+		b.WriteString(saved[next])
+		s = s[i+len(placeholder(next)):]
+		next++
+	}
+	if next != len(saved) {
+		return s, ErrProtectedRegionLost
+	}
+	b.WriteString(s)
+	return b.String(), nil
+}
+
+// checkRegions compiles the patterns of regions, with empty submatches in
+// End.
+func checkRegions(regions []Region) error {
+	for _, r := range regions {
+		begin, err := compile(r.Begin, r.Begin)
+		if err != nil {
+			return err
+		}
+		if _, err := regionEnd(begin, r.End, "", make([]int, 2*begin.NumSubexp()+2)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findRegions returns the ordered, non-overlapping spans of s described by
+// regions. Where regions start at the same position, the first one wins.
+func findRegions(s string, regions []Region) ([]span, error) {
+	begins := make([]searcher, len(regions))
+	for i, r := range regions {
+		sr, err := compileSearcher(r.Begin, r.Begin)
+		if err != nil {
+			return nil, err
+		}
+		begins[i] = sr
+	}
+
+	// next holds the next match of every begin pattern, or nil if there is
+	// none. A pattern is searched again only once a region covers the
+	// start of its match.
+	next := make([][]int, len(begins))
+	for i, sr := range begins {
+		next[i] = sr.find(s, 0)
+	}
+
+	var spans []span
+	for pos := 0; pos < len(s); {
+		region := -1
+		for i, sr := range begins {
+			if next[i] != nil && next[i][0] < pos {
+				next[i] = sr.find(s, pos)
+			}
+			if next[i] != nil && (region < 0 || next[i][0] < next[region][0]) {
+				region = i
+			}
+		}
+		if region < 0 {
+			break
+		}
+		begin := next[region]
+		if begin[1] == begin[0] {
+			// Skip empty begin matches, they cannot start a region.
+			_, width := utf8.DecodeRuneInString(s[begin[0]:])
+			pos = begin[0] + width
+			continue
+		}
+
+		end, err := regionEnd(begins[region].re, regions[region].End, s, begin)
+		if err != nil {
+			return nil, err
+		}
+		spans = append(spans, span{start: begin[0], end: end, word: region})
+		pos = end
+	}
+	return spans, nil
+}
+
+// A searcher finds the matches of a regular expression that start at any
+// position of a text.
+type searcher struct {
+	re    *regexp.Regexp
+	after *regexp.Regexp // any rune followed by re
+}
+
+func compileSearcher(regExp string, pattern string) (searcher, error) {
+	re, err := compile(regExp, pattern)
+	if err != nil {
+		return searcher{}, err
+	}
+	after, err := compile(`(?s:.)(?:`+regExp+`)`, pattern)
+	return searcher{re: re, after: after}, err
+}
+
+// find returns the submatch indices of the first match in s that starts at
+// pos or later, or nil. Matching s[pos:] would take pos for the start of
+// the text, where ^ and \b can match although they do not in s, so the
+// search starts one rune earlier and skips that rune.
+func (sr searcher) find(s string, pos int) []int {
+	if pos == 0 {
+		return sr.re.FindStringSubmatchIndex(s)
+	}
+	_, width := utf8.DecodeLastRuneInString(s[:pos])
+	from := pos - width
+	loc := sr.after.FindStringSubmatchIndex(s[from:])
+	if loc == nil {
+		return nil
+	}
+	_, width = utf8.DecodeRuneInString(s[from+loc[0]:])
+	loc[0] += width
+	for k := range loc {
+		if loc[k] >= 0 {
+			loc[k] += from
+		}
+	}
+	return loc
+}
+
+// regionEnd returns the end of the region in s whose beginning was matched
+// by begin at loc.
+func regionEnd(begin *regexp.Regexp, end string, s string, loc []int) (int, error) {
+	// Expand the submatches of begin quoted, so they match literally.
+	var quoted strings.Builder
+	indices := make([]int, len(loc))
+	for i := 0; i < len(loc); i += 2 {
+		if loc[i] < 0 {
+			indices[i], indices[i+1] = -1, -1
+			continue
+		}
+		indices[i] = quoted.Len()
+		quoted.WriteString(regexp.QuoteMeta(s[loc[i]:loc[i+1]]))
+		indices[i+1] = quoted.Len()
+	}
+	expr := string(begin.ExpandString(nil, end, quoted.String(), indices))
+
+	sr, err := compileSearcher(expr, end)
+	if err != nil {
+		return 0, err
+	}
+	if m := sr.find(s, loc[1]); m != nil {
+		return m[1], nil
+	}
+	return len(s), nil
+}
@@ -0,0 +1,185 @@
+package strdel
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_Protect_haveProtectedRegions_RegionsAreKept(t *testing.T) {
+	tests := []struct {
+		name    string
+		fn      func(string) string
+		regions []Region
+		in      string
+		want    string
+	}{
+		{
+			name:    "TrailingSpaces",
+			fn:      TrailingSpaces,
+			regions: []Region{MarkdownFence},
+			in:      "text  \n```go\ncode  \n\n```\nmore  \n",
+			want:    "text\n```go\ncode  \n\n```\nmore\n",
+		},
+		{
+			name:    "LeadingSpaces",
+			fn:      LeadingSpaces,
+			regions: []Region{MarkdownFence},
+			in:      "  text\n  ~~~~\n  code\n  ```\n  ~~~~\n  more",
+			want:    "text\n  ~~~~\n  code\n  ```\n  ~~~~\nmore",
+		},
+		{
+			name:    "EmptyLine",
+			fn:      EmptyLine,
+			regions: []Region{LaTeXVerbatim},
+			in:      "a\n\n\\begin{lstlisting}\nx\n\n\\end{verbatim}\n\\end{lstlisting}\n\nb",
+			want:    "a\n\\begin{lstlisting}\nx\n\n\\end{verbatim}\n\\end{lstlisting}\nb",
+		},
+		{
+			name:    "Numbering",
+			fn:      Numbering,
+			regions: []Region{HTMLPre},
+			in:      "1. item\n<PRE class=\"x\">\n2. kept\n</pre >\n3. item",
+			want:    "item\n<PRE class=\"x\">\n2. kept\n</pre >\nitem",
+		},
+		{
+			name:    "unterminated region",
+			fn:      TrailingSpaces,
+			regions: []Region{HTMLPre, MarkdownFence},
+			in:      "a \n<pre>b \n",
+			want:    "a\n<pre>b \n",
+		},
+		{
+			name:    "fence after region in the middle of a line",
+			fn:      TrailingSpaces,
+			regions: []Region{HTMLPre, MarkdownFence},
+			in:      "<pre>x </pre>```\n  a  \n```\n",
+			want:    "<pre>x </pre>```\n  a\n```\n",
+		},
+		{
+			name:    "code span starting in a fence",
+			fn:      InternalSpaces,
+			regions: []Region{MarkdownFence, MarkdownCodeSpan},
+			in:      "```\na  `b\n```\nCall  `f(a,  b)`  now\n",
+			want:    "```\na  `b\n```\nCall `f(a,  b)` now\n",
+		},
+		{
+			name:    "no regions",
+			fn:      TrailingSpaces,
+			regions: nil,
+			in:      "a \n<pre>b \n",
+			want:    "a\n<pre>b\n",
+		},
+	}
+
+	for _, test := range tests {
+		got, err := Protect(test.in, test.fn, test.regions...)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%s: call Protect(%#v)\n\texp: %#v\n\n\tgot: %#v", test.name, test.in, test.want, got)
+		}
+	}
+}
+
+func Test_Protect_haveManyRegionsOfSeveralKinds_RegionsAreFoundQuickly(t *testing.T) {
+	// Searching the whole rest of the text for the next fence after every
+	// region made this take seconds.
+	var in, want strings.Builder
+	in.WriteString("```\na  b\n```\n")
+	want.WriteString("```\na  b\n```\n")
+	for i := 0; i < 4000; i++ {
+		in.WriteString("Call  `f(a,  b)`  or  <pre>x  y</pre>.  \n")
+		want.WriteString("Call `f(a,  b)` or <pre>x  y</pre>.  \n")
+	}
+
+	start := time.Now()
+	got, err := Protect(in.String(), InternalSpaces, MarkdownFence, MarkdownCodeSpan, HTMLPre)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want.String() {
+		t.Errorf("call Protect: regions of %d bytes of text were not kept", in.Len())
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("call Protect: took %v for %d bytes of text", d, in.Len())
+	}
+}
+
+func Test_Protect_haveCustomRegion_EndRefersToBegin(t *testing.T) {
+	heredoc := Region{Name: "heredoc", Begin: `<<([A-Z]+)\n`, End: `(?m)^${1}`}
+	in := "x  <<EOF\n  EO  \nEOF  \ny  "
+	want := "x<<EOF\n  EO  \nEOF\ny"
+
+	got, err := Protect(in, func(s string) string {
+		return strings.ReplaceAll(s, " ", "")
+	}, heredoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("call Protect(%#v)\n\texp: %#v\n\n\tgot: %#v", in, want, got)
+	}
+}
+
+func Test_Protect_haveLostRegion_ReturnError(t *testing.T) {
+	in := "a <pre>b</pre> c"
+	for _, fn := range []func(string) string{
+		func(string) string { return "" },
+		func(s string) string { return s + s },
+	} {
+		got, err := Protect(in, fn, HTMLPre)
+		if !errors.Is(err, ErrProtectedRegionLost) {
+			t.Errorf("want ErrProtectedRegionLost, got %v", err)
+		}
+		if got != in {
+			t.Errorf("want input for lost region, got %#v", got)
+		}
+	}
+
+	var perr *PatternError
+	if _, err := Protect(in, TrailingSpaces, Region{Begin: `(`}); !errors.As(err, &perr) {
+		t.Errorf("want PatternError for invalid Begin, got %v", err)
+	}
+}
+
+func Test_RegionNamed_haveBuiltinNames_RegionsAreFound(t *testing.T) {
+	for _, name := range RegionNames() {
+		if r, ok := RegionNamed(name); !ok || r.Name != name {
+			t.Errorf("call RegionNamed(%#v): got %+v, %v", name, r, ok)
+		}
+	}
+	if _, ok := RegionNamed("no-such-region"); ok {
+		t.Errorf("call RegionNamed: want no region for unknown name")
+	}
+}
+
+func Test_Pipeline_haveProtectedRegions_RegionsAreKept(t *testing.T) {
+	p := NewPipeline()
+	if err := p.AddBuiltin("TrailingSpaces"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddWords("drop", []string{"drop"}, Options{Tidy: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Protect(MarkdownFence); err != nil {
+		t.Fatal(err)
+	}
+
+	in := "drop this  \n```\ndrop this  \n```\n"
+	want := "this\n```\ndrop this  \n```\n"
+	got, err := p.Run(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("call Run(%#v)\n\texp: %#v\n\n\tgot: %#v", in, want, got)
+	}
+
+	if err := p.Protect(Region{Begin: "a", End: "${1}("}); err == nil {
+		t.Errorf("call Protect: want error for invalid End")
+	}
+}
@@ -0,0 +1,7 @@
+//go:build race
+
+package strdel
+
+// raceEnabled reports whether the race detector is on. It allocates, so
+// allocation counts are meaningless then.
+const raceEnabled = true
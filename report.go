@@ -0,0 +1,83 @@
+package strdel
+
+import "unicode/utf8"
+
+// Deletion records a piece of text deleted from a string. It covers the
+// match only: the spaces and line breaks that Options.Tidy removes around
+// it are not reported.
+type Deletion struct {
+	Start, End   int    // byte offsets of the deleted text in the input
+	Line, Column int    // 1-based position of Start, Column counts runes
+	Text         string // the deleted text
+	Rule         string // name of the word or pattern that matched
+}
+
+// WordReport is like WordWithOptions but also returns what was deleted.
+func WordReport(s string, wordToDelete string, opts Options) (string, []Deletion, error) {
+	return WordsReport(s, []string{wordToDelete}, opts)
+}
+
+// WordsReport is like Words but also returns what was deleted.
+func WordsReport(s string, words []string, opts Options) (string, []Deletion, error) {
+	m, err := newWordMatcher(words, opts)
+	if err != nil {
+		return s, nil, err
+	}
+	spans := m.find(s)
+	return deleteSpans(s, spans, opts.Tidy), report(s, spans, func(sp span) string {
+		if opts.Name != "" {
+			return opts.Name
+		}
+		return m.words[sp.word]
+	}), nil
+}
+
+// RegExpReport is like RegExpWithOptions but also returns what was deleted.
+func RegExpReport(s string, regExp string, opts Options) (string, []Deletion, error) {
+	spans, err := findRegExp(s, regExp, opts)
+	if err != nil {
+		return s, nil, err
+	}
+	rule := regExp
+	if opts.Name != "" {
+		rule = opts.Name
+	}
+	return deleteSpans(s, spans, opts.Tidy), report(s, spans, func(span) string {
+		return rule
+	}), nil
+}
+
+// report turns the ordered spans found in s into deletions. Empty spans
+// delete nothing and are left out.
+func report(s string, spans []span, rule func(span) string) []Deletion {
+	var deletions []Deletion
+	line, column := 1, 1
+	pos := 0
+	for _, sp := range spans {
+		if sp.start == sp.end {
+			continue
+		}
+		for pos < sp.start {
+			// Only line breaks that end before the span matter, and a CR
+			// at sp.start-1 may start a CRLF.
+			content, br := nextLine(s[pos : sp.start+1])
+			end := pos + len(content) + len(br)
+			if end > sp.start {
+				column += utf8.RuneCountInString(s[pos:sp.start])
+				pos = sp.start
+				break
+			}
+			line, column = line+1, 1
+			pos = end
+		}
+		deletions = append(deletions, Deletion{
+			Start:  sp.start,
+			End:    sp.end,
+			Line:   line,
+			Column: column,
+			Text:   s[sp.start:sp.end],
+			Rule:   rule(sp),
+		})
+	}
+	return deletions
+}
@@ -0,0 +1,68 @@
+package strdel
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_WordsReport_haveSeveralWords_DeletionsAreReported(t *testing.T) {
+	in := "a very good day\nsehr schön, quite nice"
+	got, deletions, err := WordsReport(in, []string{"very", "quite", "sehr"}, Options{Tidy: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "a good day\nschön, nice"; got != want {
+		t.Errorf("call WordsReport\n\texp: %#v\n\n\tgot: %#v", want, got)
+	}
+	// The spaces removed by Tidy are not part of the deletions.
+	want := []Deletion{
+		{Start: 2, End: 6, Line: 1, Column: 3, Text: "very", Rule: "very"},
+		{Start: 16, End: 20, Line: 2, Column: 1, Text: "sehr", Rule: "sehr"},
+		{Start: 29, End: 34, Line: 2, Column: 13, Text: "quite", Rule: "quite"},
+	}
+	if !reflect.DeepEqual(want, deletions) {
+		t.Errorf("call WordsReport\n\texp: %+v\n\n\tgot: %+v", want, deletions)
+	}
+}
+
+func Test_RegExpReport_haveNamedRule_DeletionsCarryName(t *testing.T) {
+	in := "see [1]\nand [23]"
+	got, deletions, err := RegExpReport(in, `\s*\[\d+\]`, Options{Name: "footnote"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "see\nand"; got != want {
+		t.Errorf("call RegExpReport\n\texp: %#v\n\n\tgot: %#v", want, got)
+	}
+	want := []Deletion{
+		{Start: 3, End: 7, Line: 1, Column: 4, Text: " [1]", Rule: "footnote"},
+		{Start: 11, End: 16, Line: 2, Column: 4, Text: " [23]", Rule: "footnote"},
+	}
+	if !reflect.DeepEqual(want, deletions) {
+		t.Errorf("call RegExpReport\n\texp: %+v\n\n\tgot: %+v", want, deletions)
+	}
+}
+
+func Test_WordReport_haveInvalidPattern_ReturnPatternError(t *testing.T) {
+	if _, _, err := WordReport("text", `(`, Options{}); err == nil {
+		t.Errorf("call WordReport: want error")
+	}
+}
+
+func Test_RegExpReport_haveMixedLineEndings_LinesAreCounted(t *testing.T) {
+	in := "a x\r\nb x\rc x\u2028d x\u2029e x"
+	_, deletions, err := RegExpReport(in, `x`, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got [][2]int
+	for _, d := range deletions {
+		got = append(got, [2]int{d.Line, d.Column})
+	}
+	if want := [][2]int{{1, 3}, {2, 3}, {3, 3}, {4, 3}, {5, 3}}; !reflect.DeepEqual(want, got) {
+		t.Errorf("call RegExpReport(%#v): lines and columns\n\texp: %v\n\n\tgot: %v", in, want, got)
+	}
+}
@@ -0,0 +1,312 @@
+package strdel
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Rules files describe a pipeline in JSON:
+//
+//	{
+//		"protect": ["markdown-fence"],
+//		"rules": [
+//			{"name": "ads", "kind": "word", "pattern": "Advertisement",
+//			 "flags": ["ignorecase", "tidy"]},
+//			{"name": "page numbers", "kind": "regexp", "pattern": "^\\d+$",
+//			 "scope": "line"},
+//			{"name": "spaces", "kind": "builtin", "pattern": "TrailingSpaces",
+//			 "enabled": false}
+//		]
+//	}
+//
+// The kind of a rule is word, regexp or builtin, whose pattern is the name
+// of a function listed by Builtins. The flags literal, ignorecase, unicode
+// and tidy set the Options of word and regexp rules. A rule of scope line
+// runs on every line separately, without its line break; the default scope
+// is document. Disabled rules are added to the pipeline disabled. Protect
+// names built-in regions, see Regions.
+
+// A RuleError reports an invalid rules file or rule.
+type RuleError struct {
+	File string // name of the rules file, if known
+	Line int    // 1-based line of the rule or the syntax error
+	Rule string // name of the rule, if known
+	Err  error
+}
+
+func (e *RuleError) Error() string {
+	var b strings.Builder
+	b.WriteString("strdel: ")
+	if e.File != "" {
+		b.WriteString(e.File + ":")
+	}
+	fmt.Fprintf(&b, "%d: ", e.Line)
+	if e.Rule != "" {
+		fmt.Fprintf(&b, "rule %q: ", e.Rule)
+	}
+	b.WriteString(strings.TrimPrefix(e.Err.Error(), "strdel: "))
+	return b.String()
+}
+
+func (e *RuleError) Unwrap() error {
+	return e.Err
+}
+
+type rule struct {
+	Name    string   `json:"name"`
+	Kind    string   `json:"kind"`
+	Pattern string   `json:"pattern"`
+	Flags   []string `json:"flags"`
+	Scope   string   `json:"scope"`
+	Enabled *bool    `json:"enabled"`
+
+	line int
+}
+
+// LoadRulesFile reads the rules file called name, see LoadRules.
+func LoadRulesFile(name string) (*Pipeline, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return loadRules(name, data)
+}
+
+// LoadRules reads a rules file from r and returns its pipeline. All rules
+// are checked before the pipeline is returned; the errors of all invalid
+// rules are joined and each of them is a *RuleError.
+func LoadRules(r io.Reader) (*Pipeline, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return loadRules("", data)
+}
+
+func loadRules(file string, data []byte) (*Pipeline, error) {
+	f, err := parseRules(data)
+	if err != nil {
+		var located *RuleError
+		if errors.As(err, &located) {
+			located.File = file
+		}
+		return nil, err
+	}
+
+	p := NewPipeline()
+	var errs []error
+	fail := func(line int, name string, err error) {
+		errs = append(errs, &RuleError{File: file, Line: line, Rule: name, Err: err})
+	}
+
+	for _, name := range f.protect {
+		region, ok := RegionNamed(name)
+		if !ok {
+			fail(f.protectLine, "", fmt.Errorf("unknown region %q", name))
+			continue
+		}
+		if err := p.Protect(region); err != nil {
+			fail(f.protectLine, "", err)
+		}
+	}
+
+	for _, r := range f.rules {
+		run, err := r.step()
+		if err == nil {
+			err = p.add(r.Name, run)
+		}
+		if err == nil && r.Enabled != nil && !*r.Enabled {
+			err = p.Disable(r.Name)
+		}
+		if err != nil {
+			fail(r.line, r.Name, err)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return p, nil
+}
+
+type rulesFile struct {
+	protect     []string
+	protectLine int
+	rules       []rule
+}
+
+// parseRules decodes data, recording the lines the rules start on.
+func parseRules(data []byte) (*rulesFile, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	f := &rulesFile{}
+	err := func() error {
+		if err := expectDelim(dec, '{'); err != nil {
+			return err
+		}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			switch key {
+			case "protect":
+				f.protectLine = lineAt(data, valueStart(data, dec))
+				err = dec.Decode(&f.protect)
+			case "rules":
+				err = decodeRules(dec, data, &f.rules)
+			default:
+				err = fmt.Errorf("strdel: unknown field %q", key)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return expectDelim(dec, '}')
+	}()
+	var ruleErr *RuleError
+	switch {
+	case errors.As(err, &ruleErr):
+		return nil, err
+	case err != nil:
+		return nil, &RuleError{Line: lineAt(data, errorOffset(err, dec)), Err: err}
+	}
+	return f, nil
+}
+
+func decodeRules(dec *json.Decoder, data []byte, rules *[]rule) error {
+	if err := expectDelim(dec, '['); err != nil {
+		return err
+	}
+	for dec.More() {
+		start := valueStart(data, dec)
+		var r rule
+		if err := dec.Decode(&r); err != nil {
+			// Offsets of type errors are relative to the decoded value.
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &typeErr) {
+				return &RuleError{Line: lineAt(data, start+int(typeErr.Offset)), Rule: r.Name, Err: err}
+			}
+			return err
+		}
+		r.line = lineAt(data, start)
+		*rules = append(*rules, r)
+	}
+	return expectDelim(dec, ']')
+}
+
+// valueStart returns the offset of the next value dec decodes.
+func valueStart(data []byte, dec *json.Decoder) int {
+	start := int(dec.InputOffset())
+	for start < len(data) && strings.IndexByte(" \t\r\n,:", data[start]) >= 0 {
+		start++
+	}
+	return start
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != delim {
+		return fmt.Errorf("strdel: want %v, got %v", delim, tok)
+	}
+	return nil
+}
+
+// errorOffset returns the offset in the input at which err occurred.
+func errorOffset(err error, dec *json.Decoder) int {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return int(syntaxErr.Offset)
+	}
+	return int(dec.InputOffset())
+}
+
+// lineAt returns the 1-based line of offset in data.
+func lineAt(data []byte, offset int) int {
+	if offset > len(data) {
+		offset = len(data)
+	}
+	return 1 + bytes.Count(data[:offset], []byte("\n"))
+}
+
+// step checks r and returns the run function of its pipeline step.
+func (r rule) step() (func(string) (string, int, error), error) {
+	if r.Name == "" {
+		return nil, errors.New("missing name")
+	}
+	if r.Pattern == "" {
+		return nil, errors.New("missing pattern")
+	}
+
+	var opts Options
+	for _, flag := range r.Flags {
+		switch flag {
+		case "literal":
+			opts.Literal = true
+		case "ignorecase":
+			opts.IgnoreCase = true
+		case "unicode":
+			opts.Unicode = true
+		case "tidy":
+			opts.Tidy = true
+		default:
+			return nil, fmt.Errorf("unknown flag %q", flag)
+		}
+	}
+
+	var run func(string) (string, int, error)
+	var err error
+	switch r.Kind {
+	case "word":
+		run, err = wordsStep([]string{r.Pattern}, opts)
+	case "regexp":
+		run, err = regExpStep(r.Pattern, opts)
+	case "builtin":
+		if len(r.Flags) > 0 {
+			return nil, errors.New("builtin rules take no flags")
+		}
+		run, err = builtinStep(r.Pattern)
+	default:
+		return nil, fmt.Errorf("unknown kind %q, want word, regexp or builtin", r.Kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch r.Scope {
+	case "", "document":
+		return run, nil
+	case "line":
+		return perLine(run), nil
+	}
+	return nil, fmt.Errorf("unknown scope %q, want document or line", r.Scope)
+}
+
+// perLine returns a run function that applies run to every line of its
+// input, without the line break.
+func perLine(run func(string) (string, int, error)) func(string) (string, int, error) {
+	return func(in string) (string, int, error) {
+		var b strings.Builder
+		total := 0
+		for s := in; len(s) > 0; {
+			content, br := nextLine(s)
+			out, n, err := run(content)
+			if err != nil {
+				return in, total, err
+			}
+			b.WriteString(out)
+			b.WriteString(br)
+			total += n
+			s = s[len(content)+len(br):]
+		}
+		return b.String(), total, nil
+	}
+}
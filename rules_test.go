@@ -0,0 +1,123 @@
+package strdel
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const validRules = `{
+	"protect": ["markdown-fence"],
+	"rules": [
+		{"name": "ads", "kind": "word", "pattern": "advertisement",
+		 "flags": ["ignorecase", "tidy"]},
+		{"name": "page numbers", "kind": "regexp", "pattern": "^\\d+$",
+		 "scope": "line"},
+		{"name": "trailing", "kind": "builtin", "pattern": "TrailingSpaces"},
+		{"name": "leading", "kind": "builtin", "pattern": "LeadingSpaces",
+		 "enabled": false}
+	]
+}`
+
+func Test_LoadRules_haveValidRules_PipelineRunsThem(t *testing.T) {
+	p, err := LoadRules(strings.NewReader(validRules))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	in := "  Text ADVERTISEMENT  \n12\n```\n12  \n```\n"
+	want := "  Text\n\n```\n12  \n```\n"
+	got, err := p.Run(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("call Run(%#v)\n\texp: %#v\n\n\tgot: %#v", in, want, got)
+	}
+
+	var names []string
+	for _, st := range p.Stats() {
+		names = append(names, st.Name)
+		if st.Enabled != (st.Name != "leading") {
+			t.Errorf("step %q: Enabled is %v", st.Name, st.Enabled)
+		}
+	}
+	if got := strings.Join(names, ","); got != "ads,page numbers,trailing,leading" {
+		t.Errorf("steps %s", got)
+	}
+}
+
+func Test_LoadRulesFile_haveInvalidRules_AllErrorsHaveLines(t *testing.T) {
+	rules := `{
+	"rules": [
+		{"name": "ok", "kind": "word", "pattern": "fine"},
+		{"name": "bad regexp", "kind": "regexp",
+		 "pattern": "a[b"},
+		{"name": "ok", "kind": "word", "pattern": "again"},
+		{"name": "bad kind", "kind": "phrase", "pattern": "x"},
+		{"name": "bad flag", "kind": "word", "pattern": "x", "flags": ["loud"]},
+		{"name": "bad scope", "kind": "word", "pattern": "x", "scope": "page"},
+		{"name": "bad builtin", "kind": "builtin", "pattern": "Nothing"},
+		{"kind": "word", "pattern": "x"}
+	],
+	"protect": ["nowhere"]
+}`
+	name := filepath.Join(t.TempDir(), "rules.json")
+	if err := os.WriteFile(name, []byte(rules), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := LoadRulesFile(name)
+	if p != nil || err == nil {
+		t.Fatalf("want error, got pipeline")
+	}
+	want := []struct {
+		line int
+		rule string
+	}{
+		{13, ""}, {4, "bad regexp"}, {6, "ok"}, {7, "bad kind"}, {8, "bad flag"},
+		{9, "bad scope"}, {10, "bad builtin"}, {11, ""},
+	}
+	errs := err.(interface{ Unwrap() []error }).Unwrap()
+	if len(errs) != len(want) {
+		t.Fatalf("want %d errors, got:\n%v", len(want), err)
+	}
+	for i, e := range errs {
+		var ruleErr *RuleError
+		if !errors.As(e, &ruleErr) {
+			t.Fatalf("error %d is no RuleError: %v", i, e)
+		}
+		if ruleErr.File != name || ruleErr.Line != want[i].line || ruleErr.Rule != want[i].rule {
+			t.Errorf("error %d\n\texp: line %d, rule %q\n\tgot: %v", i, want[i].line, want[i].rule, e)
+		}
+	}
+
+	var patternErr *PatternError
+	if !errors.As(errs[1], &patternErr) || patternErr.Pattern != "a[b" {
+		t.Errorf("want PatternError for bad regexp, got %v", errs[1])
+	}
+	if msg := errs[1].Error(); !strings.HasPrefix(msg, "strdel: "+name+`:4: rule "bad regexp": invalid pattern`) {
+		t.Errorf("message %q", msg)
+	}
+}
+
+func Test_LoadRules_haveMalformedJSON_ErrorHasLine(t *testing.T) {
+	tests := []struct {
+		in   string
+		line int
+	}{
+		{"{\n\"rules\": [\n{\"name\": \"a\",}\n]}", 3},
+		{"{\n\"rules\": [\n{\"name\": 1}\n]}", 3},
+		{"{\n\"rules\": [],\n\"color\": \"red\"\n}", 3},
+		{"[]", 1},
+	}
+	for _, test := range tests {
+		_, err := LoadRules(strings.NewReader(test.in))
+		var ruleErr *RuleError
+		if !errors.As(err, &ruleErr) || ruleErr.Line != test.line {
+			t.Errorf("LoadRules(%#v): want error on line %d, got %v", test.in, test.line, err)
+		}
+	}
+}
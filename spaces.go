@@ -0,0 +1,164 @@
+package strdel
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// WhitespaceClass is a set of characters treated as white space.
+type WhitespaceClass int
+
+const (
+	// ASCIISpace is space, tab and form feed, the class of TrailingSpaces
+	// and LeadingSpaces.
+	ASCIISpace WhitespaceClass = iota
+
+	// UnicodeSpace adds the other Unicode White_Space characters that do
+	// not break lines, like the no-break, en, em and ideographic spaces,
+	// and the invisible zero width space U+200B, word joiner U+2060 and
+	// byte order mark U+FEFF.
+	UnicodeSpace
+)
+
+// isUnicodeSpace reports whether r is in UnicodeSpace.
+func isUnicodeSpace(r rune) bool {
+	switch r {
+	case '\t', '\v', '\f', ' ', '\u00A0', '\u1680', '\u202F', '\u205F', '\u3000',
+		'\u200B', '\u2060', '\uFEFF':
+		return true
+	}
+	return '\u2000' <= r && r <= '\u200A'
+}
+
+// SpaceOptions control TrailingSpacesWithOptions and
+// LeadingSpacesWithOptions. The zero value gives the behavior of
+// TrailingSpaces and LeadingSpaces.
+type SpaceOptions struct {
+	// Class is the set of characters removed.
+	Class WhitespaceClass
+}
+
+// TrailingSpacesWithOptions removes trailing white spaces of opts.Class
+// from string s.
+func TrailingSpacesWithOptions(s string, opts SpaceOptions) string {
+	if opts.Class != UnicodeSpace {
+		return TrailingSpaces(s)
+	}
+	return mapLines(s, func(line string, last bool) string {
+		if last {
+			return line
+		}
+		return strings.TrimRightFunc(line, isUnicodeSpace)
+	})
+}
+
+// LeadingSpacesWithOptions removes leading white spaces of opts.Class from
+// string s.
+func LeadingSpacesWithOptions(s string, opts SpaceOptions) string {
+	if opts.Class != UnicodeSpace {
+		return LeadingSpaces(s)
+	}
+	return mapLines(s, func(line string, _ bool) string {
+		return strings.TrimLeftFunc(line, isUnicodeSpace)
+	})
+}
+
+// InternalSpacesOptions control InternalSpacesWithOptions. The zero value
+// gives the behavior of InternalSpaces.
+type InternalSpacesOptions struct {
+	// Class is the set of characters collapsed.
+	Class WhitespaceClass
+
+	// CollapseIndent collapses the indentation of lines to a single
+	// space, too.
+	CollapseIndent bool
+
+	// Regions are left intact, like MarkdownCodeSpan.
+	Regions []Region
+}
+
+// InternalSpaces replaces every run of spaces, tabs and form feeds between
+// two other characters of a line of string s by a single space. The
+// indentation, trailing spaces and line breaks are left alone.
+// Example: "  a  \t b " --> "  a b "
+func InternalSpaces(s string) string {
+	return internalSpaces(s, isASCIISpace, false)
+}
+
+// InternalSpacesWithOptions is like InternalSpaces, configured by opts.
+func InternalSpacesWithOptions(s string, opts InternalSpacesOptions) (string, error) {
+	isSpace := isASCIISpace
+	if opts.Class == UnicodeSpace {
+		isSpace = isUnicodeSpace
+	}
+	return Protect(s, func(s string) string {
+		return internalSpaces(s, isSpace, opts.CollapseIndent)
+	}, opts.Regions...)
+}
+
+func internalSpaces(s string, isSpace func(rune) bool, collapseIndent bool) string {
+	return mapLines(s, func(line string, _ bool) string {
+		var b strings.Builder
+		last := 0 // end of the text copied to b
+		i := 0
+		if !collapseIndent {
+			i = len(line) - len(strings.TrimLeftFunc(line, isSpace))
+		}
+		for i < len(line) {
+			r, n := utf8.DecodeRuneInString(line[i:])
+			if !isSpace(r) {
+				i += n
+				continue
+			}
+			end := len(line) - len(strings.TrimLeftFunc(line[i:], isSpace))
+			if end == len(line) {
+				break
+			}
+			if line[i:end] != " " {
+				b.WriteString(line[last:i])
+				b.WriteByte(' ')
+				last = end
+			}
+			i = end
+		}
+		if last == 0 {
+			return line
+		}
+		b.WriteString(line[last:])
+		return b.String()
+	})
+}
+
+// blankLine reports whether line, without its line break, consists of
+// white space of class only.
+func blankLine(line string, class WhitespaceClass) bool {
+	if class == UnicodeSpace {
+		return strings.TrimLeftFunc(line, isUnicodeSpace) == ""
+	}
+	return strings.TrimLeft(line, " \t\f") == ""
+}
+
+func isASCIISpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\f'
+}
+
+// NormalizeSpaces replaces the non-ASCII spaces of UnicodeSpace in string
+// s by an ASCII space and deletes the invisible zero width space, word
+// joiner and byte order mark. Tabs and line breaks are left alone.
+// Example: "10\u00A0km" --> "10 km"
+func NormalizeSpaces(s string) string {
+	return strings.Map(normalizeSpace, s)
+}
+
+func normalizeSpace(r rune) rune {
+	switch {
+	case r < 0x80:
+		return r
+	case r == '\u200B' || r == '\u2060' || r == '\uFEFF':
+		return -1
+	case r == '\u00A0' || r == '\u1680' || '\u2000' <= r && r <= '\u200A' ||
+		r == '\u202F' || r == '\u205F' || r == '\u3000':
+		return ' '
+	}
+	return r
+}
@@ -0,0 +1,98 @@
+package strdel
+
+import "testing"
+
+func Test_SpacesWithOptions_haveUnicodeSpaces_SpacesAreRemoved(t *testing.T) {
+	in := "\uFEFF\u00A0Title\u3000\n\u2003 text\u200B\u00A0\r\n\tcode\u2060 \nkeep\u2028 \n"
+
+	tests := []struct {
+		name  string
+		fn    func(string, SpaceOptions) string
+		class WhitespaceClass
+		want  string
+	}{
+		{"TrailingSpaces", TrailingSpacesWithOptions, ASCIISpace,
+			"\uFEFF\u00A0Title\u3000\n\u2003 text\u200B\u00A0\r\n\tcode\u2060\nkeep\u2028\n"},
+		{"TrailingSpaces", TrailingSpacesWithOptions, UnicodeSpace,
+			"\uFEFF\u00A0Title\n\u2003 text\r\n\tcode\nkeep\u2028\n"},
+		{"LeadingSpaces", LeadingSpacesWithOptions, ASCIISpace,
+			"\uFEFF\u00A0Title\u3000\n\u2003 text\u200B\u00A0\r\ncode\u2060 \nkeep\u2028\n"},
+		{"LeadingSpaces", LeadingSpacesWithOptions, UnicodeSpace,
+			"Title\u3000\ntext\u200B\u00A0\r\ncode\u2060 \nkeep\u2028\n"},
+	}
+	for _, test := range tests {
+		got := test.fn(in, SpaceOptions{Class: test.class})
+		if got != test.want {
+			t.Errorf("call %sWithOptions(%#v), class %d\n\texp: %#v\n\n\tgot: %#v",
+				test.name, in, test.class, test.want, got)
+		}
+	}
+	if TrailingSpacesWithOptions(in, SpaceOptions{}) != TrailingSpaces(in) ||
+		LeadingSpacesWithOptions(in, SpaceOptions{}) != LeadingSpaces(in) {
+		t.Errorf("zero SpaceOptions differ from TrailingSpaces and LeadingSpaces")
+	}
+}
+
+func Test_NormalizeSpaces_haveExoticSpaces_AsciiSpacesAreLeft(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"plain\ttext\n", "plain\ttext\n"},
+		{"10\u00A0km, 5\u202F%", "10 km, 5 %"},
+		{"\u3000Ideographic\u2002en\u2003em\u2009thin", " Ideographic en em thin"},
+		{"\uFEFFzero\u200Bwidth\u2060joiner", "zerowidthjoiner"},
+		{"line\u2028separator and caf\u00E9", "line\u2028separator and caf\u00E9"},
+	}
+	for _, test := range tests {
+		if got := NormalizeSpaces(test.in); got != test.want {
+			t.Errorf("call NormalizeSpaces(%#v)\n\texp: %#v\n\n\tgot: %#v", test.in, test.want, got)
+		}
+	}
+}
+
+func Test_InternalSpaces_haveSpaceRuns_RunsAreCollapsed(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"a b\n", "a b\n"},
+		{"a    b\t\tc \f d", "a b c d"},
+		{"    indented  code  \n\tnext\t\tline\r\n", "    indented code  \n\tnext line\r\n"},
+		{"a  \n  b", "a  \n  b"},
+		{"a\tb", "a b"},
+		{"a\u00A0\u00A0b", "a\u00A0\u00A0b"},
+	}
+	for _, test := range tests {
+		if got := InternalSpaces(test.in); got != test.want {
+			t.Errorf("call InternalSpaces(%#v)\n\texp: %#v\n\n\tgot: %#v", test.in, test.want, got)
+		}
+	}
+}
+
+func Test_InternalSpacesWithOptions_haveOptions_OptionsAreUsed(t *testing.T) {
+	tests := []struct {
+		in, want string
+		opts     InternalSpacesOptions
+	}{
+		{"  a\u00A0\u2003b  c", "  a b c", InternalSpacesOptions{Class: UnicodeSpace}},
+		{"  a   b\n\t\tc", " a b\n c", InternalSpacesOptions{CollapseIndent: true}},
+		{"Call  `f(a,   b)`  and  `` x  `y` ``.", "Call `f(a,   b)` and `` x  `y` ``.",
+			InternalSpacesOptions{Regions: []Region{MarkdownCodeSpan}}},
+		{"a  b\n```\nx    y\n```\n`c  d`  e",
+			"a b\n```\nx    y\n```\n`c  d` e",
+			InternalSpacesOptions{Regions: []Region{MarkdownFence, MarkdownCodeSpan}}},
+		{"`a  b\n c  d`  e", "`a  b\n c  d` e", InternalSpacesOptions{Regions: []Region{MarkdownCodeSpan}}},
+		{"Use  `x  y\n\nlater  `z`  here", "Use `x y\n\nlater `z` here",
+			InternalSpacesOptions{Regions: []Region{MarkdownCodeSpan}}},
+		{"`a\r\n\r\nb  c`", "`a\r\n\r\nb c`", InternalSpacesOptions{Regions: []Region{MarkdownCodeSpan}}},
+	}
+	for _, test := range tests {
+		got, err := InternalSpacesWithOptions(test.in, test.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.want {
+			t.Errorf("call InternalSpacesWithOptions(%#v, %+v)\n\texp: %#v\n\n\tgot: %#v", test.in, test.opts, test.want, got)
+		}
+	}
+}
@@ -2,128 +2,508 @@
 package strdel
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
+	"regexp/syntax"
 	"strings"
+	"sync"
+
+	"github.com/frankMilde/strdel/latex"
 )
 
+// PatternError reports a word or regular expression that could not be
+// compiled. Offset is the byte position in Pattern where the offending
+// expression starts.
+type PatternError struct {
+	Pattern string
+	Offset  int
+	Err     error
+}
+
+func (e *PatternError) Error() string {
+	return fmt.Sprintf("strdel: invalid pattern %q at offset %d: %v",
+		e.Pattern, e.Offset, e.Err)
+}
+
+func (e *PatternError) Unwrap() error {
+	return e.Err
+}
+
+// compile compiles regExp, which is built around the caller-supplied
+// pattern, or takes it from the pattern cache. Errors are returned as
+// *PatternError relative to pattern.
+func compile(regExp string, pattern string) (*regexp.Regexp, error) {
+	if re := patterns.get(regExp); re != nil {
+		return re, nil
+	}
+	re, err := regexp.Compile(regExp)
+	if err == nil {
+		patterns.add(regExp, re)
+		return re, nil
+	}
+
+	// Report the error of the bare pattern, not of the expression we
+	// wrapped around it.
+	if _, perr := syntax.Parse(pattern, syntax.Perl); perr != nil {
+		err = perr
+	}
+	offset := 0
+	if serr, ok := err.(*syntax.Error); ok {
+		if i := strings.Index(pattern, serr.Expr); i > 0 {
+			offset = i
+		}
+	}
+	return nil, &PatternError{Pattern: pattern, Offset: offset, Err: err}
+}
+
+// Word deletes all occurrences of wordToDelete from string s. wordToDelete
+// is a regular expression that must match at word boundaries. Word panics if
+// wordToDelete does not compile; use WordE to get an error instead.
 func Word(s string, wordToDelete string) string {
-	regWordToDelete := regexp.MustCompile(`\b` + wordToDelete + `\b`)
-	s = regWordToDelete.ReplaceAllString(s, "")
+	s, err := WordE(s, wordToDelete)
+	if err != nil {
+		panic(err)
+	}
 	return s
 }
 
+// WordE is like Word but returns a *PatternError if wordToDelete does not
+// compile.
+func WordE(s string, wordToDelete string) (string, error) {
+	return WordWithOptions(s, wordToDelete, Options{})
+}
+
+// RegExp deletes all matches of regExp from string s. RegExp panics if
+// regExp does not compile; use RegExpE to get an error instead.
 func RegExp(s string, regExp string) string {
-	regWordToDelete := regexp.MustCompile(regExp)
-	s = regWordToDelete.ReplaceAllString(s, "")
+	s, err := RegExpE(s, regExp)
+	if err != nil {
+		panic(err)
+	}
 	return s
 }
 
+// RegExpE is like RegExp but returns a *PatternError if regExp does not
+// compile.
+func RegExpE(s string, regExp string) (string, error) {
+	return RegExpWithOptions(s, regExp, Options{})
+}
+
 // Duplicates deletes duplicate from a string slice.
 func Duplicates(strings []string) []string {
-	// Use map to record duplicates as we find them.
-	encountered := map[string]struct{}{}
-	result := []string{}
-
-	for _, s := range strings {
-		if _, ok := encountered[s]; !ok {
-			encountered[s] = struct{}{}
-			result = append(result, s)
-		}
-	}
-
-	return result
+	return DuplicatesFunc(strings, func(s string) string { return s })
 }
 
 // Numbering removes leading enumerations at the begin of a line from string
 // s. Example: 3. Heading --> Heading
+// NumberingWithOptions removes other kinds of list markers.
 func Numbering(s string) string {
-	digitsAndDotAtStartOfLine := `(?m)^[ \t\r\f]*\d+\.[ \t\r\f]*`
-	return RegExp(s, digitsAndDotAtStartOfLine)
+	return mapLines(s, func(line string, _ bool) string {
+		i := len(line) - len(strings.TrimLeft(line, " \t\f"))
+		digits := i
+		for i < len(line) && '0' <= line[i] && line[i] <= '9' {
+			i++
+		}
+		if i == digits || i == len(line) || line[i] != '.' {
+			return line
+		}
+		return strings.TrimLeft(line[i+1:], " \t\f")
+	})
 }
 
 // TrailingSpaces removes trailing non-line breaking white spaces from
-// string s
+// string s. TrailingSpacesWithOptions also removes Unicode spaces.
 func TrailingSpaces(s string) string {
-	// convert unicode char \u00A0 = &nbsp = 'non-breaking space' to space
-	//s = strings.Replace(s, " \n", "\n", -1)
-
-	regSpace := regexp.MustCompile(`[ \t\r\f]+\n`)
-	s = regSpace.ReplaceAllString(s, "\n")
-
-	return s
+	return mapLines(s, func(line string, last bool) string {
+		if last {
+			return line
+		}
+		return strings.TrimRight(line, " \t\f")
+	})
 }
 
 // LeadingSpaces removes leading non-line breaking white spaces from string
-// s
+// s. LeadingSpacesWithOptions also removes Unicode spaces.
 func LeadingSpaces(s string) string {
-	regSpace := regexp.MustCompile(`^[ \t\r\f]+`)
-	s = regSpace.ReplaceAllString(s, "")
-	regSpace = regexp.MustCompile(`\n[ \t\r\f]+`)
-	s = regSpace.ReplaceAllString(s, "\n")
-	return s
+	return mapLines(s, func(line string, _ bool) string {
+		return strings.TrimLeft(line, " \t\f")
+	})
 }
 
-// EmptyBrackets changes multiline empty `{\n\n}` into `{}`
+// EmptyBrackets changes multiline empty `{\n\n}` and `{\\}` into `{}`.
+// Escaped braces, comments, math and verbatim content are left alone.
 func EmptyBrackets(s string) string {
-	reg := regexp.MustCompile(`\{(\s+)\}`)
-	s = reg.ReplaceAllString(s, "{}")
+	if strings.IndexByte(s, '}') < 0 {
+		return s
+	}
 
-	reg = regexp.MustCompile(`\{\\\\\}`)
-	//fmt.Println(reg.FindAllString(s, -1))
-	s = reg.ReplaceAllString(s, "{}")
-	return s
+	out := make([]byte, 0, len(s))
+	var open []int // end of every unclosed '{' in out
+	for _, tok := range latex.Tokenize(s) {
+		switch {
+		case tok.Math:
+		case tok.Kind == latex.BeginGroup:
+			open = append(open, len(out)+1)
+		case tok.Kind == latex.EndGroup && len(open) > 0:
+			content := string(out[open[len(open)-1]:])
+			if content == `\\` || content != "" && strings.Trim(content, " \t\f\r\n") == "" {
+				out = out[:open[len(open)-1]]
+			}
+			open = open[:len(open)-1]
+		}
+		out = append(out, tok.Text...)
+	}
+	return string(out)
 }
 
+// Logger receives messages about the changes made by cleanup functions.
+// It is satisfied by *log.Logger.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+var (
+	loggerMu sync.Mutex
+	logger   Logger
+)
+
+// SetLogger makes cleanup functions report their changes to l. A nil
+// logger, the default, disables logging.
+func SetLogger(l Logger) {
+	loggerMu.Lock()
+	defer loggerMu.Unlock()
+	logger = l
+}
+
+func logf(format string, v ...interface{}) {
+	loggerMu.Lock()
+	l := logger
+	loggerMu.Unlock()
+	if l != nil {
+		l.Printf(format, v...)
+	}
+}
+
+// EmptyLinesInMacros removes blank lines, which would end the paragraph,
+// from the arguments of macros like `\emph{a\n\nb}`. Arguments may be
+// nested to any depth and include optional arguments in brackets. Blank
+// lines outside of arguments are left alone.
 func EmptyLinesInMacros(s string) string {
+	if strings.IndexByte(s, '\\') < 0 {
+		return s
+	}
 
-	pattern := `(\\\b[a-z]+\b\{(?s).*?)[\r\n]((?m)^\s*$[\r\n]*)[\r\n]\s*(.*?\})`
+	type group struct {
+		end   latex.Kind // EndGroup or EndOptional
+		macro string     // macro whose argument contains the group, or ""
+	}
+	var (
+		open   []group
+		closed = closedArgument{index: -1}
+		line   = 1
+		out    = make([]byte, 0, len(s))
+	)
 
-	replace := `${1} 
-	${3}`
+	tokens := latex.Tokenize(s)
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		// Line breaks may also be part of other tokens, like verbatim
+		// environments.
+		line += countBreaks(tok.Text)
+		switch tok.Kind {
+		case latex.BeginGroup, latex.BeginOptional:
+			macro := ""
+			if len(open) > 0 {
+				macro = open[len(open)-1].macro
+			}
+			if macro == "" {
+				macro = argumentOf(tokens, i, closed)
+			}
+			if tok.Kind == latex.BeginOptional && macro == "" {
+				break
+			}
+			end := latex.EndGroup
+			if tok.Kind == latex.BeginOptional {
+				end = latex.EndOptional
+			}
+			open = append(open, group{end: end, macro: macro})
+		case latex.EndGroup, latex.EndOptional:
+			if len(open) == 0 || open[len(open)-1].end != tok.Kind {
+				break
+			}
+			if macro := open[len(open)-1].macro; macro != "" {
+				closed = closedArgument{index: i, macro: macro}
+			}
+			open = open[:len(open)-1]
+		case latex.Newline:
+			if len(open) == 0 || open[len(open)-1].macro == "" {
+				break
+			}
+			j := i + 1
+			for j < len(tokens) && tokens[j].Kind == latex.Space {
+				j++
+			}
+			if j < len(tokens) && tokens[j].Kind == latex.Newline {
+				// The Logger may keep its arguments, and the text may
+				// be a view of a byte slice from EmptyLinesInMacrosBytes.
+				logf("strdel: EmptyLinesInMacros: removed blank line %d in argument of %s",
+					line, strings.Clone(open[len(open)-1].macro))
+				i = j - 1
+				continue
+			}
+		}
+		out = append(out, tok.Text...)
+	}
+	return string(out)
+}
 
-	emptyLineInMacro := regexp.MustCompile(pattern)
+// argumentOf returns the name of the macro that takes the group opened at
+// tokens[i] as argument, or "". A group is an argument if it follows a
+// macro or a previous argument, separated by spaces and at most one line
+// break.
+func argumentOf(tokens []latex.Token, i int, last closedArgument) string {
+	j, breaks := i-1, 0
+	for ; j >= 0 && tokens[j].IsSpace(); j-- {
+		if tokens[j].Kind == latex.Newline {
+			breaks++
+		}
+	}
+	switch {
+	case j < 0 || breaks > 1:
+		return ""
+	case tokens[j].Kind == latex.ControlWord:
+		return tokens[j].Text
+	case j == last.index:
+		return last.macro
+	}
+	return ""
+}
 
-	matches := emptyLineInMacro.FindAllStringSubmatch(s, -1)
-	fmt.Println(matches)
-	fmt.Println("\n\n\n\nNew Match:\n\n")
-	return emptyLineInMacro.ReplaceAllString(s, replace)
+// closedArgument is the token that closed the last argument of macro.
+type closedArgument struct {
+	index int
+	macro string
 }
 
+// EmptyMacros removes macros with an empty argument like `\emph{}`, together
+// with a following `\\`. Macros that only contain empty macros are removed
+// up to nestingDepth levels deep.
 func EmptyMacros(s string, nestingDepth int) string {
 
-	simpleMacro := regexp.MustCompile(`\\\b([a-z]+)\b\{\}(\s*\\\\)?`)
 	for i := 0; i < nestingDepth; i++ {
-		s = simpleMacro.ReplaceAllString(s, "")
+		s = removeEmptyMacros(s)
 	}
 	return s
 }
 
+// ErrNoFixedPoint is returned by EmptyMacrosUntilStable if the text still
+// changes after the maximum number of passes.
+var ErrNoFixedPoint = errors.New("strdel: text still changes after maximum number of passes")
+
+// EmptyMacrosUntilStable removes empty macros like EmptyMacros, repeating
+// until nothing changes anymore or maxPasses passes are done. In the latter
+// case the text of the last pass is returned with ErrNoFixedPoint.
+func EmptyMacrosUntilStable(s string, maxPasses int) (string, error) {
+	for i := 0; i < maxPasses; i++ {
+		next := removeEmptyMacros(s)
+		if next == s {
+			return s, nil
+		}
+		s = next
+	}
+	if removeEmptyMacros(s) != s {
+		return s, ErrNoFixedPoint
+	}
+	return s, nil
+}
+
+// removeEmptyMacros does a single pass of EmptyMacros.
+func removeEmptyMacros(s string) string {
+	if !strings.Contains(s, "{}") {
+		return s
+	}
+
+	tokens := latex.Tokenize(s)
+	var b strings.Builder
+	for i := 0; i < len(tokens); i++ {
+		if len(tokens) >= i+3 && isMacro(tokens[i]) &&
+			tokens[i+1].Kind == latex.BeginGroup && tokens[i+2].Kind == latex.EndGroup {
+			i = afterTexLinebreak(tokens, i+3) - 1
+			continue
+		}
+		b.WriteString(tokens[i].Text)
+	}
+	return b.String()
+}
+
+// EmptyNestedMacros removes empty macros like EmptyMacros at any nesting
+// depth in a single pass over s, by matching braces instead of repeating
+// the replacement.
+func EmptyNestedMacros(s string) string {
+	if !strings.Contains(s, "{}") {
+		return s
+	}
+
+	type group struct {
+		macro   int // start of the macro owning the group in out, or -1
+		content int // start of the group content in out
+	}
+
+	tokens := latex.Tokenize(s)
+	out := make([]byte, 0, len(s))
+	var open []group
+	for i := 0; i < len(tokens); i++ {
+		switch tok := tokens[i]; {
+		case tok.Math:
+		case tok.Kind == latex.BeginGroup:
+			g := group{macro: -1, content: len(out) + 1}
+			if i > 0 && isMacro(tokens[i-1]) {
+				g.macro = len(out) - len(tokens[i-1].Text)
+			}
+			open = append(open, g)
+		case tok.Kind == latex.EndGroup && len(open) > 0:
+			g := open[len(open)-1]
+			open = open[:len(open)-1]
+			if g.macro >= 0 && g.content == len(out) {
+				out = out[:g.macro]
+				i = afterTexLinebreak(tokens, i+1) - 1
+				continue
+			}
+		}
+		out = append(out, tokens[i].Text...)
+	}
+	return string(out)
+}
+
+// isMacro reports whether tok is a control word of lowercase letters
+// outside of math, whose empty argument may be removed.
+func isMacro(tok latex.Token) bool {
+	if tok.Kind != latex.ControlWord || tok.Math {
+		return false
+	}
+	for i := 1; i < len(tok.Text); i++ {
+		if tok.Text[i] < 'a' || tok.Text[i] > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+// afterTexLinebreak returns the index after a `\\` that follows tokens[i:]
+// after optional spaces, or i if there is none.
+func afterTexLinebreak(tokens []latex.Token, i int) int {
+	j := i
+	for j < len(tokens) && tokens[j].IsSpace() {
+		j++
+	}
+	if j < len(tokens) && tokens[j].Is(latex.ControlSymbol, `\\`) {
+		return j + 1
+	}
+	return i
+}
+
 // SpaceBeforeClosingBrackets deletes linebreaks and spaces before closing
 // brackets "}".
 func SpaceBeforeClosingBrackets(s string) string {
+	isLinebreak := func(tok latex.Token) bool {
+		return tok.Is(latex.ControlSymbol, `\\`)
+	}
+	isSpace := latex.Token.IsSpace
 
-	linebreaks := regexp.MustCompile(`(\\\\)+\}`)
-	s = linebreaks.ReplaceAllString(s, `}\\`)
+	s = rewriteBeforeClosingBracket(s, isLinebreak, func([]latex.Token) string {
+		return `}\\`
+	})
+	s = rewriteBeforeClosingBracket(s, isSpace, func([]latex.Token) string {
+		return "} "
+	})
+	s = rewriteBeforeClosingBracket(s, func(tok latex.Token) bool {
+		return isSpace(tok) || isLinebreak(tok)
+	}, func(run []latex.Token) string {
+		last := run[len(run)-1].Text
+		if isSpace(run[len(run)-1]) {
+			last = last[len(last)-1:]
+		}
+		return "} " + last
+	})
+	return s
+}
 
-	spaces := regexp.MustCompile(`\s+\}`)
-	s = spaces.ReplaceAllString(s, "} ")
+// rewriteBeforeClosingBracket replaces every run of tokens accepted by inRun
+// that directly precedes a closing bracket, together with the bracket, by
+// replace(run). The line break that ends a comment never joins a run.
+func rewriteBeforeClosingBracket(s string, inRun func(latex.Token) bool, replace func([]latex.Token) string) string {
+	if strings.IndexByte(s, '}') < 0 {
+		return s
+	}
 
-	spacesAndLineBreaks := regexp.MustCompile(`(\s|\\\\)+\}`)
-	s = spacesAndLineBreaks.ReplaceAllString(s, `} $1`)
-	return s
+	tokens := latex.Tokenize(s)
+	var b strings.Builder
+	var run []latex.Token
+	for i, tok := range tokens {
+		endsComment := tok.Kind == latex.Newline && i > 0 && tokens[i-1].Kind == latex.Comment
+		switch {
+		case tok.Kind == latex.EndGroup && !tok.Math && len(run) > 0:
+			b.WriteString(replace(run))
+			run = run[:0]
+		case inRun(tok) && !tok.Math && !endsComment:
+			run = append(run, tok)
+		default:
+			for _, r := range run {
+				b.WriteString(r.Text)
+			}
+			run = run[:0]
+			b.WriteString(tok.Text)
+		}
+	}
+	for _, r := range run {
+		b.WriteString(r.Text)
+	}
+	return b.String()
 }
 
+// EmptyLine deletes blank lines together with their line break, and the
+// line break at the end of s.
 func EmptyLine(s string) string {
-	re := regexp.MustCompile("(?m)^\\s*$[\r\n]*")
-
-	return strings.Trim(re.ReplaceAllString(s, ""), "\r\n")
+	var b strings.Builder
+	last := 0 // end of the text copied to b
+	for i := 0; i < len(s); {
+		line, br := nextLine(s[i:])
+		end := i + len(line) + len(br)
+		if blankLine(line, ASCIISpace) {
+			if last == 0 {
+				b.Grow(len(s))
+			}
+			b.WriteString(s[last:i])
+			last = end
+		}
+		i = end
+	}
+	if last == 0 {
+		return s[:len(s)-trailingBreakLen(s)]
+	}
+	b.WriteString(s[last:])
+	out := b.String()
+	return out[:len(out)-trailingBreakLen(out)]
 }
 
+// SpaceAfterOpeningBrackets deletes linebreaks and spaces after opening
+// brackets "{" that follow other text.
 func SpaceAfterOpeningBrackets(s string) string {
+	if strings.IndexByte(s, '{') < 0 {
+		return s
+	}
 
-	reg := regexp.MustCompile(`(\S)\{\s+`)
-	s = reg.ReplaceAllString(s, "$1{")
-
-	return s
+	tokens := latex.Tokenize(s)
+	var b strings.Builder
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		b.WriteString(tok.Text)
+		if tok.Kind == latex.BeginGroup && !tok.Math && i > 0 && !tokens[i-1].IsSpace() {
+			for i+1 < len(tokens) && tokens[i+1].IsSpace() {
+				i++
+			}
+		}
+	}
+	return b.String()
 }
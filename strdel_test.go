@@ -350,8 +350,8 @@ func Test_EmptyLinesInMacros_haveEmptyNewLines_NoNewLinesInMacro(t *testing.T) {
 			Test
 			`,
 			Want: `Test
-			 \emph{ Test1 Test1 
-			 Test1 }
+			 \emph{ Test1 Test1
+		Test1 }
 
 			Test
 			`,
@@ -455,13 +455,11 @@ let one of its researchers publish a paper proposing a more efficient way to bui
 			Want: `Test
 					\textbf{\\
 					In Conclusion:
-		
 					Capitalism as “Social Pathology”} \\
 		
 		      \textbf{-STRUCTURAL CLASSISM, THE STATE AND WAR-
 		          \\
 		          \\
-		
 		         \footnote{
 		         Source: “Man's place in the animal world”,  \emph{What is Man? And other Irreverent Essays, Mark Twain, 1896, p.157}}
 		          \\
@@ -480,6 +478,76 @@ let one of its researchers publish a paper proposing a more efficient way to bui
 
 }
 
+func Test_EmptyLinesInMacros_haveOptionalAndNestedArguments_BlankLinesAreRemoved(t *testing.T) {
+	tests := testutils.ConversionTests{
+		{ // optional argument, argument on the next line
+			In:   "\\includegraphics\n[width=1,\n\n height=2]\n {a\n \n b}",
+			Want: "\\includegraphics\n[width=1,\n height=2]\n {a\n b}",
+		},
+		{ // nested groups and several blank lines
+			In:   "\\href{a}{\\textbf{b\n\n\t\n{c\r\n\r\nd}}}",
+			Want: "\\href{a}{\\textbf{b\n{c\r\nd}}}",
+		},
+		{ // no arguments
+			In:   "a\n\n{b\n\nc} [d\n\ne] \\par\n\n{f\n\ng}",
+			Want: "a\n\n{b\n\nc} [d\n\ne] \\par\n\n{f\n\ng}",
+		},
+		{ // verbatim and unbalanced brackets
+			In:   "\\emph{\\begin{verbatim}x\n\ny\\end{verbatim} ]\n\n}",
+			Want: "\\emph{\\begin{verbatim}x\n\ny\\end{verbatim} ]\n}",
+		},
+	}
+
+	for _, test := range tests {
+		got := EmptyLinesInMacros(test.In)
+		if err := testutils.MustBeEqual(got, test.Want); err != nil {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d:\n\ncall EmptyLinesInMacros(%#v)\n\texp: %#v\n\n\tgot: %#v\n\n",
+				filepath.Base(file), line, test.In, test.Want, got)
+			t.FailNow()
+		}
+	}
+	testutils.Cleanup()
+}
+
+type recordingLogger []string
+
+func (l *recordingLogger) Printf(format string, v ...interface{}) {
+	*l = append(*l, fmt.Sprintf(format, v...))
+}
+
+func Test_EmptyLinesInMacros_haveLogger_RemovalsAreLogged(t *testing.T) {
+	var l recordingLogger
+	SetLogger(&l)
+	defer SetLogger(nil)
+
+	in := "a\n\\emph{b\n\nc}\n\n"
+	EmptyLinesInMacros(in)
+	want := recordingLogger{`strdel: EmptyLinesInMacros: removed blank line 3 in argument of \emph`}
+	if !reflect.DeepEqual(want, l) {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\ncall EmptyLinesInMacros(%#v), logged\n\texp: %#v\n\n\tgot: %#v\n\n",
+			filepath.Base(file), line, in, want, l)
+		t.FailNow()
+	}
+}
+
+func Test_EmptyLinesInMacros_haveLoggerAndVerbatim_LineNumberCountsVerbatimLines(t *testing.T) {
+	var l recordingLogger
+	SetLogger(&l)
+	defer SetLogger(nil)
+
+	in := "\\begin{verbatim}\n\n\\end{verbatim}\\emph{a\n\nb}"
+	EmptyLinesInMacros(in)
+	want := recordingLogger{`strdel: EmptyLinesInMacros: removed blank line 4 in argument of \emph`}
+	if !reflect.DeepEqual(want, l) {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\ncall EmptyLinesInMacros(%#v), logged\n\texp: %#v\n\n\tgot: %#v\n\n",
+			filepath.Base(file), line, in, want, l)
+		t.FailNow()
+	}
+}
+
 func Test_SpaceAfterOpeningBrackets__haveMultilinedSpaceAfterBrackets_spaceAndNewlinesAreRemoved(t *testing.T) {
 	tests := testutils.ConversionTests{
 		{
@@ -537,3 +605,191 @@ asdf
 	testutils.Cleanup()
 
 }
+
+func Test_Word_haveWordsInText_WordsAreRemoved(t *testing.T) {
+	tests := testutils.ConversionTests{
+		{
+			In:   `a very good day`,
+			Want: `a  good day`,
+		},
+		{
+			In:   `everyone is very, very happy`,
+			Want: `everyone is ,  happy`,
+		},
+	}
+
+	for _, test := range tests {
+		got := Word(test.In, "very")
+		if !reflect.DeepEqual(test.Want, got) {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d:\n\ncall Word(%#v)\n\texp: %#v\n\n\tgot: %#v\n\n",
+				filepath.Base(file), line, test.In, test.Want, got)
+			t.FailNow()
+		}
+	}
+	testutils.Cleanup()
+
+}
+
+func Test_WordE_haveInvalidPattern_ReturnPatternError(t *testing.T) {
+	tests := []struct {
+		pattern string
+		offset  int
+	}{
+		{pattern: `C++`, offset: 1},
+		{pattern: `(price`, offset: 0},
+		{pattern: `a[b`, offset: 1},
+	}
+
+	for _, test := range tests {
+		got, err := WordE("some text", test.pattern)
+		perr, ok := err.(*PatternError)
+		if !ok || perr.Pattern != test.pattern || perr.Offset != test.offset || got != "some text" {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d:\n\ncall WordE(%#v)\n\texp: %#v, *PatternError at offset %d\n\n\tgot: %#v, %#v\n\n",
+				filepath.Base(file), line, test.pattern, "some text", test.offset, got, err)
+			t.FailNow()
+		}
+	}
+}
+
+func Test_RegExpE_haveInvalidPattern_ReturnPatternError(t *testing.T) {
+	_, err := RegExpE("some text", `x*+`)
+	if perr, ok := err.(*PatternError); !ok || perr.Offset != 1 {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\ncall RegExpE(%#v)\n\texp: *PatternError at offset 1\n\n\tgot: %#v\n\n",
+			filepath.Base(file), line, `x*+`, err)
+		t.FailNow()
+	}
+
+	got, err := RegExpE("some text", `\s*text`)
+	if err != nil || got != "some" {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\ncall RegExpE(%#v)\n\texp: %#v\n\n\tgot: %#v, %v\n\n",
+			filepath.Base(file), line, `\s*text`, "some", got, err)
+		t.FailNow()
+	}
+}
+
+func Test_Word_haveInvalidPattern_Panics(t *testing.T) {
+	defer func() {
+		r := recover()
+		if _, ok := r.(*PatternError); !ok {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d:\n\ncall Word(%#v)\n\texp: panic with *PatternError\n\n\tgot: panic with %#v\n\n",
+				filepath.Base(file), line, `C++`, r)
+			t.FailNow()
+		}
+	}()
+	Word("some text", `C++`)
+}
+
+func Test_EmptyNestedMacros_haveDeeplyNestedEmptyMacros_AreRemoved(t *testing.T) {
+	tests := testutils.ConversionTests{
+		{
+			In:   `test \underline{} test`,
+			Want: `test  test`,
+		},
+		{
+			In:   `test \underline{\textbf{\emph{}}} test`,
+			Want: `test  test`,
+		},
+		{
+			In:   `test \underline{\textbf{test}} test`,
+			Want: `test \underline{\textbf{test}} test`,
+		},
+		{ // a following tex linebreak goes with the macro
+			In:   "\\textbf{\\emph{} \\\\} \\\\\nnext",
+			Want: "\nnext",
+		},
+		{ // plain groups and escaped braces are no macro arguments
+			In:   `\href{url}{} \{\emph{\}} {\bf{}}`,
+			Want: `\href{url}{} \{\emph{\}} {}`,
+		},
+		{ // a tex linebreak is no macro
+			In:   `a\\emph{} b`,
+			Want: `a\\emph{} b`,
+		},
+	}
+
+	for _, test := range tests {
+		got := EmptyNestedMacros(test.In)
+		if !reflect.DeepEqual(test.Want, got) {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d:\n\ncall EmptyNestedMacros(%#v)\n\texp: %#v\n\n\tgot: %#v\n\n",
+				filepath.Base(file), line, test.In, test.Want, got)
+			t.FailNow()
+		}
+	}
+	testutils.Cleanup()
+
+}
+
+func Test_EmptyMacrosUntilStable_haveNestedEmptyMacros_AreRemovedOrErrorIsReturned(t *testing.T) {
+	in := `test \underline{\textbf{\emph{}}} test`
+	tests := []struct {
+		maxPasses int
+		want      string
+		err       error
+	}{
+		{maxPasses: 10, want: `test  test`},
+		{maxPasses: 3, want: `test  test`},
+		{maxPasses: 2, want: `test \underline{} test`, err: ErrNoFixedPoint},
+	}
+
+	for _, test := range tests {
+		got, err := EmptyMacrosUntilStable(in, test.maxPasses)
+		if got != test.want || err != test.err {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d:\n\ncall EmptyMacrosUntilStable(%#v, %d)\n\texp: %#v, %v\n\n\tgot: %#v, %v\n\n",
+				filepath.Base(file), line, in, test.maxPasses, test.want, test.err, got, err)
+			t.FailNow()
+		}
+	}
+}
+
+func Test_LatexFunctions_haveEscapesCommentsVerbatimAndMath_TheseAreLeftAlone(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(string) string
+		In   string
+		Want string
+	}{
+		{
+			name: "EmptyBrackets",
+			fn:   EmptyBrackets,
+			In:   "\\{  \\} {  } % {  }\n\\verb|{ }| $x^{ }$",
+			Want: "\\{  \\} {} % {  }\n\\verb|{ }| $x^{ }$",
+		},
+		{
+			name: "EmptyNestedMacros",
+			fn:   EmptyNestedMacros,
+			In:   "\\emph{\\textbf{}} % \\emph{}\n\\begin{verbatim}\\emph{}\\end{verbatim} $\\mathrm{}$",
+			Want: " % \\emph{}\n\\begin{verbatim}\\emph{}\\end{verbatim} $\\mathrm{}$",
+		},
+		{
+			name: "SpaceAfterOpeningBrackets",
+			fn:   SpaceAfterOpeningBrackets,
+			In:   "\\emph{ a} \\{ b\\} $x^{ 2}$ % c{ d\n\\verb|x{ y|",
+			Want: "\\emph{a} \\{ b\\} $x^{ 2}$ % c{ d\n\\verb|x{ y|",
+		},
+		{ // the brace must not move into the comment
+			name: "SpaceBeforeClosingBrackets",
+			fn:   SpaceBeforeClosingBrackets,
+			In:   "\\emph{a % comment\n} \\{ b \\} $x^{2 }$",
+			Want: "\\emph{a % comment\n} \\{ b \\} $x^{2 }$",
+		},
+	}
+
+	for _, test := range tests {
+		got := test.fn(test.In)
+		if !reflect.DeepEqual(test.Want, got) {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d:\n\ncall %s(%#v)\n\texp: %#v\n\n\tgot: %#v\n\n",
+				filepath.Base(file), line, test.name, test.In, test.Want, got)
+			t.FailNow()
+		}
+	}
+	testutils.Cleanup()
+
+}
@@ -0,0 +1,158 @@
+package strdel
+
+import (
+	"bufio"
+	"errors"
+	"io"
+)
+
+// A LineStep cleans a single line of text. The line includes its line
+// break, unless it is the last line of a text that does not end in one. A
+// step returns "" to drop the line.
+type LineStep func(line string) string
+
+// Line steps of the line-oriented cleanup functions. Applied to every line
+// of a text they give the same result as the functions applied to the
+// whole text, except that EmptyLineStep keeps the line break at the end of
+// the text, which EmptyLine removes.
+var (
+	TrailingSpacesStep  LineStep = TrailingSpaces
+	LeadingSpacesStep   LineStep = LeadingSpaces
+	NumberingStep       LineStep = Numbering
+	NormalizeSpacesStep LineStep = NormalizeSpaces
+	InternalSpacesStep  LineStep = InternalSpaces
+	EmptyLineStep       LineStep = emptyLine
+)
+
+func emptyLine(line string) string {
+	if blankLine(line[:len(line)-trailingBreakLen(line)], ASCIISpace) {
+		return ""
+	}
+	return line
+}
+
+// WordStep returns a line step that deletes words as Words does. Words
+// cannot match across line breaks.
+func WordStep(words []string, opts Options) (LineStep, error) {
+	m, err := newWordMatcher(words, opts)
+	if err != nil {
+		return nil, err
+	}
+	return func(line string) string {
+		return deleteSpans(line, m.find(line), opts.Tidy)
+	}, nil
+}
+
+// MaxLineLength is the length of the longest line a Writer buffers.
+const MaxLineLength = 1 << 20
+
+// ErrLineTooLong is returned by a Writer for lines longer than
+// MaxLineLength.
+var ErrLineTooLong = errors.New("strdel: line too long")
+
+// A Writer applies line steps to the text written to it and writes the
+// result to an underlying writer. Lines end at any of the line endings of
+// LineEnding. Only the current line is held in memory, so a match can never
+// be split between two writes. Close must be called to write the last line.
+type Writer struct {
+	w       *bufio.Writer
+	steps   []LineStep
+	line    []byte // incomplete line of previous writes
+	scanned int    // length of line in which no line break starts
+	err     error
+}
+
+// NewWriter returns a Writer that writes to w the text written to it,
+// passed through steps in order.
+func NewWriter(w io.Writer, steps ...LineStep) *Writer {
+	return &Writer{w: bufio.NewWriter(w), steps: steps}
+}
+
+// Write cleans all complete lines of p. The rest is kept until the line is
+// completed by following writes or by Close.
+func (w *Writer) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	data, from := p, 0
+	if len(w.line) > 0 {
+		// Only the new bytes and a line break split between the writes
+		// at the end of the buffered line are searched.
+		w.line = append(w.line, p...)
+		data, from = w.line, w.scanned
+	}
+	for {
+		_, end := lineBreak(data[from:], false)
+		if end < 0 {
+			break
+		}
+		end += from
+		if err := w.writeLine(data[:end]); err != nil {
+			return consumed(p, data), err
+		}
+		data, from = data[end:], 0
+	}
+	if len(data) > MaxLineLength {
+		w.err = ErrLineTooLong
+		return consumed(p, data), w.err
+	}
+	w.line = append(w.line[:0], data...)
+	// A CR, or the first two bytes of LS or PS, may start a line break.
+	w.scanned = 0
+	if len(w.line) > 2 {
+		w.scanned = len(w.line) - 2
+	}
+	return len(p), nil
+}
+
+// consumed returns the number of bytes of p before rest, the unwritten end
+// of the buffered line and p.
+func consumed(p, rest []byte) int {
+	if len(rest) > len(p) {
+		return 0
+	}
+	return len(p) - len(rest)
+}
+
+// Close writes the last line, if it does not end in a line break, and
+// flushes the output. It does not close the underlying writer.
+func (w *Writer) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+	if len(w.line) > 0 {
+		if err := w.writeLine(w.line); err != nil {
+			return err
+		}
+		w.line, w.scanned = nil, 0
+	}
+	if err := w.w.Flush(); err != nil {
+		w.err = err
+	}
+	return w.err
+}
+
+func (w *Writer) writeLine(line []byte) error {
+	s := string(line)
+	for _, step := range w.steps {
+		if s == "" {
+			return nil
+		}
+		s = step(s)
+	}
+	if _, err := w.w.WriteString(s); err != nil {
+		w.err = err
+	}
+	return w.err
+}
+
+// Copy copies src to dst, passing every line through steps in order. It
+// returns the number of bytes read from src.
+func Copy(dst io.Writer, src io.Reader, steps ...LineStep) (int64, error) {
+	w := NewWriter(dst, steps...)
+	n, err := io.Copy(w, src)
+	if cerr := w.Close(); err == nil {
+		err = cerr
+	}
+	return n, err
+}
@@ -0,0 +1,150 @@
+package strdel
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func Test_Copy_haveLineSteps_SameResultAsStringFunctions(t *testing.T) {
+	texts := []string{
+		"",
+		"no line break  ",
+		"  1. first  \n\t2.second \r\n \f\n\n3 not numbered\n  ",
+		"\n\n  a \n \t \n\nb\n\n",
+		"a\n\u00A0\n\v\nb\n",
+		mixed,
+	}
+	tests := []struct {
+		name string
+		fn   func(string) string
+		step LineStep
+
+		keepsFinalBreak bool // the step keeps the line break at the end
+	}{
+		{"TrailingSpaces", TrailingSpaces, TrailingSpacesStep, false},
+		{"LeadingSpaces", LeadingSpaces, LeadingSpacesStep, false},
+		{"Numbering", Numbering, NumberingStep, false},
+		{"NormalizeSpaces", NormalizeSpaces, NormalizeSpacesStep, false},
+		{"InternalSpaces", InternalSpaces, InternalSpacesStep, false},
+		{"EmptyLine", EmptyLine, EmptyLineStep, true},
+	}
+
+	for _, test := range tests {
+		for _, in := range texts {
+			var out bytes.Buffer
+			n, err := Copy(&out, iotest.OneByteReader(strings.NewReader(in)), test.step)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != int64(len(in)) {
+				t.Errorf("%s: Copy read %d bytes, want %d", test.name, n, len(in))
+			}
+			got := out.String()
+			if test.keepsFinalBreak {
+				got = got[:len(got)-trailingBreakLen(got)]
+			}
+			if want := test.fn(in); got != want {
+				t.Errorf("%s: call Copy(%#v)\n\texp: %#v\n\n\tgot: %#v", test.name, in, want, got)
+			}
+		}
+	}
+}
+
+func Test_Copy_haveSeveralSteps_StepsRunInOrder(t *testing.T) {
+	word, err := WordStep([]string{"Advertisement", "spam"}, Options{Tidy: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := "  1. Advertisement\n\n \t\n  2. Some spam text  \nlast spam"
+	want := "Some text\nlast"
+
+	// Write in chunks that split lines and words.
+	var out bytes.Buffer
+	w := NewWriter(&out, LeadingSpacesStep, NumberingStep, word, TrailingSpacesStep, EmptyLineStep)
+	for i := 0; i < len(in); i += 5 {
+		end := i + 5
+		if end > len(in) {
+			end = len(in)
+		}
+		if _, err := w.Write([]byte(in[i:end])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); got != want {
+		t.Errorf("call Writer(%#v)\n\texp: %#v\n\n\tgot: %#v", in, want, got)
+	}
+}
+
+func Test_Writer_haveLongLine_ReturnError(t *testing.T) {
+	var out bytes.Buffer
+	w := NewWriter(&out, TrailingSpacesStep)
+
+	line := strings.Repeat("x", MaxLineLength/2)
+	if _, err := w.Write([]byte(line)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte(line + "x")); !errors.Is(err, ErrLineTooLong) {
+		t.Errorf("want ErrLineTooLong, got %v", err)
+	}
+	if err := w.Close(); !errors.Is(err, ErrLineTooLong) {
+		t.Errorf("Close: want ErrLineTooLong, got %v", err)
+	}
+
+	if _, err := Copy(errWriter{}, strings.NewReader("a\n"), TrailingSpacesStep); err == nil {
+		t.Errorf("Copy: want error of the underlying writer")
+	}
+}
+
+func Test_Writer_haveLineBreaksSplitBetweenWrites_LinesAreFound(t *testing.T) {
+	in := "long line a\r\nb\rc\u2028d\u2029\r\re"
+	want := []string{"long line a\r\n", "b\r", "c\u2028", "d\u2029", "\r", "\r", "e"}
+
+	for size := 1; size <= 6; size++ {
+		var lines []string
+		w := NewWriter(io.Discard, func(line string) string {
+			lines = append(lines, line)
+			return line
+		})
+		for i := 0; i < len(in); i += size {
+			end := i + size
+			if end > len(in) {
+				end = len(in)
+			}
+			if _, err := w.Write([]byte(in[i:end])); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(want, lines) {
+			t.Errorf("writes of %d bytes\n\texp: %#v\n\n\tgot: %#v", size, want, lines)
+		}
+	}
+}
+
+func BenchmarkWriter_longLine(b *testing.B) {
+	chunk := []byte(strings.Repeat("x", 16))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		w := NewWriter(io.Discard, TrailingSpacesStep)
+		for j := 0; j < 1<<12; j++ {
+			w.Write(chunk)
+		}
+		w.Close()
+	}
+}
+
+type errWriter struct{}
+
+func (errWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
@@ -0,0 +1,440 @@
+package strdel
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Options control how WordWithOptions, Words and RegExpWithOptions match.
+// The zero value gives the behavior of Word and RegExp.
+type Options struct {
+	// Literal treats the word or pattern as plain text instead of a
+	// regular expression. A literal word matches only where it is not
+	// directly preceded or followed by a word character, which also
+	// works for words that start or end with punctuation like "C++",
+	// "e.g." or "$price".
+	Literal bool
+
+	// IgnoreCase matches case-insensitively using Unicode simple case
+	// folding, so "straße" also matches "STRAẞE".
+	IgnoreCase bool
+
+	// Unicode makes words match only where they are not directly
+	// preceded or followed by a Unicode letter, number, mark or
+	// underscore, instead of at the ASCII-only boundaries of \b. It has
+	// no effect on RegExpWithOptions.
+	Unicode bool
+
+	// Tidy cleans up the gap every deletion leaves behind: the spaces
+	// around it collapse, spaces before ,.;:!? and at the start or end
+	// of the line are removed, and a line that became blank is deleted
+	// together with its line break.
+	Tidy bool
+
+	// Name labels the deletions returned by the report functions. It
+	// defaults to the word or pattern that matched.
+	Name string
+}
+
+// WordWithOptions deletes all occurrences of wordToDelete from string s as
+// configured by opts.
+func WordWithOptions(s string, wordToDelete string, opts Options) (string, error) {
+	return Words(s, []string{wordToDelete}, opts)
+}
+
+// Words deletes all occurrences of the given words from string s in a
+// single pass over s. At a position where several words match, the longest
+// plain text word and the first regular expression wins.
+func Words(s string, words []string, opts Options) (string, error) {
+	if absent(s, words, opts) {
+		return s, nil
+	}
+	m, err := newWordMatcher(words, opts)
+	if err != nil {
+		return s, err
+	}
+	return deleteSpans(s, m.find(s), opts.Tidy), nil
+}
+
+// absent reports whether none of words occurs in s, which is much cheaper
+// to check than finding the words at their boundaries. Words that are
+// regular expressions are never absent.
+func absent(s string, words []string, opts Options) bool {
+	for _, w := range words {
+		switch {
+		case !opts.Literal && regexp.QuoteMeta(w) != w:
+			return false
+		case opts.IgnoreCase && containsFold(s, w):
+			return false
+		case !opts.IgnoreCase && strings.Contains(s, w):
+			return false
+		}
+	}
+	return true
+}
+
+// containsFold reports whether s contains word under simple case folding.
+func containsFold(s, word string) bool {
+	if word == "" {
+		return true
+	}
+	first, n := utf8.DecodeRuneInString(word)
+	first = foldRune(first)
+	for i := 0; i < len(s); {
+		r, width := utf8.DecodeRuneInString(s[i:])
+		i += width
+		if foldRune(r) == first && hasPrefixFold(s[i:], word[n:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasPrefixFold reports whether s begins with prefix under simple case
+// folding.
+func hasPrefixFold(s, prefix string) bool {
+	for _, p := range prefix {
+		r, width := utf8.DecodeRuneInString(s)
+		if width == 0 || foldRune(r) != foldRune(p) {
+			return false
+		}
+		s = s[width:]
+	}
+	return true
+}
+
+// wordMatcher finds the occurrences of a set of words. Plain text words are
+// searched with an Aho-Corasick automaton, everything else with a single
+// regular expression.
+type wordMatcher struct {
+	opts     Options
+	words    []string
+	ac       *automaton
+	re       *regexp.Regexp // finds matches, or candidates if anchored is set
+	anchored *regexp.Regexp // checks a candidate in Unicode mode
+	group    []int          // capture group of each word in re or anchored
+}
+
+func newWordMatcher(words []string, opts Options) (*wordMatcher, error) {
+	m := &wordMatcher{opts: opts}
+	plain := true
+	for _, w := range Duplicates(words) {
+		if w == "" {
+			continue
+		}
+		m.words = append(m.words, w)
+		plain = plain && (opts.Literal || regexp.QuoteMeta(w) == w)
+	}
+	if len(m.words) == 0 {
+		return m, nil
+	}
+
+	if plain && (len(m.words) > 1 || opts.Unicode) {
+		fold := func(r rune) rune { return r }
+		if opts.IgnoreCase {
+			fold = foldRune
+		}
+		m.ac = newAutomaton(m.words, fold)
+		return m, nil
+	}
+
+	flags := ""
+	if opts.IgnoreCase {
+		flags = `(?i)`
+	}
+	firstGroup := 1
+	if opts.Unicode {
+		firstGroup = 2
+	}
+
+	alternatives := make([]string, len(m.words))
+	m.group = make([]int, len(m.words))
+	group := firstGroup
+	for i, w := range m.words {
+		expr := opts.wordRegExp(w)
+		if opts.Unicode {
+			expr = opts.regExp(w)
+		}
+		re, err := compile(flags+expr, w)
+		if err != nil {
+			return nil, err
+		}
+		if len(m.words) == 1 && !opts.Unicode {
+			m.re = re
+			return m, nil
+		}
+		alternatives[i] = `(` + expr + `)`
+		m.group[i] = group
+		group += 1 + re.NumSubexp()
+	}
+
+	var err error
+	if m.re, err = compile(flags+`(?:`+strings.Join(alternatives, "|")+`)`, ""); err != nil {
+		return nil, err
+	}
+	if opts.Unicode {
+		// RE2 has no Unicode word boundaries, so the end of a word is
+		// checked by consuming the character following it.
+		m.anchored, err = compile(flags+`^(`+strings.Join(alternatives, "|")+
+			`)(?:[^\pL\pN\pM_]|$)`, "")
+		if err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// find returns the non-overlapping occurrences of the words in s.
+func (m *wordMatcher) find(s string) []span {
+	switch {
+	case len(m.words) == 0:
+		return nil
+	case m.ac != nil:
+		return m.findPlain(s)
+	case m.anchored != nil:
+		return m.findUnicode(s)
+	}
+
+	var found []span
+	for _, loc := range m.re.FindAllStringSubmatchIndex(s, -1) {
+		found = append(found, span{start: loc[0], end: loc[1], word: m.matched(loc)})
+	}
+	return found
+}
+
+// matched returns the index of the word that matched in the submatch
+// indices loc.
+func (m *wordMatcher) matched(loc []int) int {
+	for i, g := range m.group {
+		if loc[2*g] >= 0 {
+			return i
+		}
+	}
+	return 0
+}
+
+// findPlain picks the leftmost-longest occurrences that are found by the
+// automaton and stand at word boundaries.
+func (m *wordMatcher) findPlain(s string) []span {
+	var found []span
+	end := 0
+	for _, sp := range m.ac.find(s) {
+		if sp.start < end || !m.atBoundaries(s, sp) {
+			continue
+		}
+		found = append(found, sp)
+		end = sp.end
+	}
+	return found
+}
+
+// findUnicode searches candidates with m.re and keeps those that m.anchored
+// confirms at Unicode word boundaries.
+func (m *wordMatcher) findUnicode(s string) []span {
+	var found []span
+	for pos := 0; pos < len(s); {
+		loc := m.re.FindStringIndex(s[pos:])
+		if loc == nil {
+			break
+		}
+		start := pos + loc[0]
+		before, _ := utf8.DecodeLastRuneInString(s[:start])
+		if start == 0 || !m.isWordChar(before) {
+			sub := m.anchored.FindStringSubmatchIndex(s[start:])
+			if sub != nil && sub[3] > 0 {
+				sp := span{start: start, end: start + sub[3], word: m.matched(sub)}
+				found = append(found, sp)
+				pos = sp.end
+				continue
+			}
+		}
+		_, width := utf8.DecodeRuneInString(s[start:])
+		pos = start + width
+	}
+	return found
+}
+
+// atBoundaries reports whether the match sp is delimited like the
+// expression returned by wordRegExp, or by Unicode word boundaries.
+func (m *wordMatcher) atBoundaries(s string, sp span) bool {
+	first, _ := utf8.DecodeRuneInString(s[sp.start:])
+	last, _ := utf8.DecodeLastRuneInString(s[sp.start:sp.end])
+	before, _ := utf8.DecodeLastRuneInString(s[:sp.start])
+	after, _ := utf8.DecodeRuneInString(s[sp.end:])
+	if sp.start == 0 {
+		before = ' '
+	}
+	if sp.end == len(s) {
+		after = ' '
+	}
+
+	if m.opts.Literal || m.opts.Unicode {
+		return !m.isWordChar(before) && !m.isWordChar(after)
+	}
+	return isWordChar(first) != isWordChar(before) &&
+		isWordChar(last) != isWordChar(after)
+}
+
+func (m *wordMatcher) isWordChar(r rune) bool {
+	if m.opts.Unicode {
+		return r == '_' || unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.IsMark(r)
+	}
+	return isWordChar(r)
+}
+
+// foldRune maps r to the smallest rune of its simple case folding orbit, so
+// that runes equal under simple folding map to the same rune.
+func foldRune(r rune) rune {
+	if r < utf8.RuneSelf {
+		if 'a' <= r && r <= 'z' {
+			r -= 'a' - 'A'
+		}
+		return r
+	}
+	min := r
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		if f < min {
+			min = f
+		}
+	}
+	return min
+}
+
+// deleteSpans returns s without the bytes covered by the ordered,
+// non-overlapping spans. If tidy is set, the gap left by every span is
+// cleaned up as described for Options.Tidy.
+func deleteSpans(s string, spans []span, tidy bool) string {
+	if len(spans) == 0 {
+		return s
+	}
+	b := make([]byte, 0, len(s))
+	last := 0
+	for _, sp := range spans {
+		if sp.end <= last || tidy && sp.start == sp.end {
+			continue
+		}
+		if sp.start > last {
+			b = append(b, s[last:sp.start]...)
+		}
+		last = sp.end
+		if tidy {
+			b, last = tidyGap(b, s, last)
+		}
+	}
+	b = append(b, s[last:]...)
+	return string(b)
+}
+
+// tidyGap cleans up the gap between the output b and the rest of s starting
+// at next. It returns the new output and the position in s to continue at.
+func tidyGap(b []byte, s string, next int) ([]byte, int) {
+	l := len(b)
+	for l > 0 && isHorizontalSpace(b[l-1]) {
+		l--
+	}
+	r := next
+	for r < len(s) && isHorizontalSpace(s[r]) {
+		r++
+	}
+	atLineStart := l == 0 || trailingBreakLen(b[:l]) > 0
+	atLineEnd := r == len(s) || breakLen(s[r:]) > 0
+
+	switch {
+	case atLineStart && atLineEnd:
+		// The line became blank, remove it with its line break.
+		b = b[:l]
+		switch {
+		case r < len(s):
+			r += breakLen(s[r:])
+		case l > 0:
+			b = b[:l-trailingBreakLen(b)]
+		}
+		return b, r
+	case atLineEnd:
+		return b[:l], r
+	case atLineStart:
+		return b, r
+	case strings.IndexByte(",.;:!?", s[r]) >= 0:
+		return b[:l], r
+	case l < len(b):
+		return b, r
+	}
+	return b, next
+}
+
+func isHorizontalSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\f' || c == '\v'
+}
+
+// RegExpWithOptions deletes all matches of regExp from string s as
+// configured by opts.
+func RegExpWithOptions(s string, regExp string, opts Options) (string, error) {
+	if opts.Literal && absent(s, []string{regExp}, opts) {
+		return s, nil
+	}
+	spans, err := findRegExp(s, regExp, opts)
+	if err != nil {
+		return s, err
+	}
+	return deleteSpans(s, spans, opts.Tidy), nil
+}
+
+// findRegExp returns the matches of regExp in s.
+func findRegExp(s string, regExp string, opts Options) ([]span, error) {
+	if opts.Literal && regExp == "" {
+		return nil, nil
+	}
+	expr := opts.regExp(regExp)
+	if opts.IgnoreCase {
+		expr = `(?i)` + expr
+	}
+	re, err := compile(expr, regExp)
+	if err != nil {
+		return nil, err
+	}
+
+	var spans []span
+	for _, loc := range re.FindAllStringIndex(s, -1) {
+		spans = append(spans, span{start: loc[0], end: loc[1]})
+	}
+	return spans, nil
+}
+
+// regExp returns the expression matching pattern.
+func (opts Options) regExp(pattern string) string {
+	if opts.Literal {
+		return regexp.QuoteMeta(pattern)
+	}
+	return pattern
+}
+
+// wordRegExp returns the expression matching word at word boundaries.
+func (opts Options) wordRegExp(word string) string {
+	if !opts.Literal {
+		return `\b` + word + `\b`
+	}
+
+	// A word character at the edge of the word needs a word boundary, a
+	// non-word character needs its neighbour to be a non-word character
+	// as well, which is what \B asserts there.
+	first, _ := utf8.DecodeRuneInString(word)
+	last, _ := utf8.DecodeLastRuneInString(word)
+	return boundary(first) + regexp.QuoteMeta(word) + boundary(last)
+}
+
+func boundary(edge rune) string {
+	if isWordChar(edge) {
+		return `\b`
+	}
+	return `\B`
+}
+
+// isWordChar reports whether r is in the ASCII word class \w.
+func isWordChar(r rune) bool {
+	return r == '_' ||
+		'0' <= r && r <= '9' ||
+		'a' <= r && r <= 'z' ||
+		'A' <= r && r <= 'Z'
+}
@@ -0,0 +1,323 @@
+package strdel
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/frankMilde/rol/testutils"
+)
+
+func Test_WordWithOptions_haveLiteralPunctuatedWords_WordsAreRemoved(t *testing.T) {
+	tests := []struct {
+		word string
+		In   string
+		Want string
+	}{
+		{ // trailing non-word characters
+			word: `C++`,
+			In:   `C++ and C++11 are not C, but C++.`,
+			Want: ` and C++11 are not C, but .`,
+		},
+		{ // inner and trailing dots
+			word: `e.g.`,
+			In:   `fruits, e.g. apples (e.g.) or e.g.x`,
+			Want: `fruits,  apples () or e.g.x`,
+		},
+		{ // leading non-word character
+			word: `$price`,
+			In:   `The $price is $priced at a$price.`,
+			Want: `The  is $priced at a$price.`,
+		},
+		{ // metacharacters only
+			word: `.*`,
+			In:   `match .* but not a.*b`,
+			Want: `match  but not a.*b`,
+		},
+		{ // plain words behave like Word
+			word: `very`,
+			In:   `a very good day, everyone`,
+			Want: `a  good day, everyone`,
+		},
+	}
+
+	for _, test := range tests {
+		got, err := WordWithOptions(test.In, test.word, Options{Literal: true})
+		if err != nil || !reflect.DeepEqual(test.Want, got) {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d:\n\ncall WordWithOptions(%#v, %#v)\n\texp: %#v\n\n\tgot: %#v, %v\n\n",
+				filepath.Base(file), line, test.In, test.word, test.Want, got, err)
+			t.FailNow()
+		}
+	}
+	testutils.Cleanup()
+
+}
+
+func Test_RegExpWithOptions_haveLiteralPattern_PatternIsNotInterpreted(t *testing.T) {
+	tests := testutils.ConversionTests{
+		{
+			In:   `price: $(a+b)*2`,
+			Want: `price: *2`,
+		},
+	}
+
+	for _, test := range tests {
+		got, err := RegExpWithOptions(test.In, `$(a+b)`, Options{Literal: true})
+		if err != nil || !reflect.DeepEqual(test.Want, got) {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d:\n\ncall RegExpWithOptions(%#v)\n\texp: %#v\n\n\tgot: %#v, %v\n\n",
+				filepath.Base(file), line, test.In, test.Want, got, err)
+			t.FailNow()
+		}
+	}
+	testutils.Cleanup()
+
+}
+
+func Test_Words_haveSeveralWords_AllWordsAreRemoved(t *testing.T) {
+	tests := []struct {
+		words []string
+		opts  Options
+		In    string
+		Want  string
+	}{
+		{
+			words: []string{`very`, `quite`, `really`},
+			In:    `a very good, quite nice and really long day`,
+			Want:  `a  good,  nice and  long day`,
+		},
+		{ // longest literal word wins
+			words: []string{`New`, `New York`, `C++`},
+			opts:  Options{Literal: true},
+			In:    `New York, New Jersey and C++ in New Yorker`,
+			Want:  `,  Jersey and  in  Yorker`,
+		},
+		{ // regular expressions keep their own word boundaries
+			words: []string{`colou?r`, `\d+`},
+			In:    `color 42 colours colour x42`,
+			Want:  `  colours  x42`,
+		},
+		{ // nothing to delete
+			words: []string{``},
+			In:    `unchanged`,
+			Want:  `unchanged`,
+		},
+	}
+
+	for _, test := range tests {
+		got, err := Words(test.In, test.words, test.opts)
+		if err != nil || !reflect.DeepEqual(test.Want, got) {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d:\n\ncall Words(%#v, %#v)\n\texp: %#v\n\n\tgot: %#v, %v\n\n",
+				filepath.Base(file), line, test.In, test.words, test.Want, got, err)
+			t.FailNow()
+		}
+	}
+	testutils.Cleanup()
+
+}
+
+func Test_Words_haveInvalidWord_ReturnPatternErrorForThatWord(t *testing.T) {
+	_, err := Words("text", []string{`fine`, `bro(ken`}, Options{})
+	perr, ok := err.(*PatternError)
+	if !ok || perr.Pattern != `bro(ken` {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\ncall Words(%#v)\n\texp: *PatternError for %#v\n\n\tgot: %#v\n\n",
+			filepath.Base(file), line, []string{`fine`, `bro(ken`}, `bro(ken`, err)
+		t.FailNow()
+	}
+}
+
+// blocklist returns n distinct words and a text containing some of them.
+func blocklist(n int) ([]string, string) {
+	words := make([]string, n)
+	for i := range words {
+		words[i] = fmt.Sprintf("tracker%03d", i)
+	}
+	text := ""
+	for i := 0; i < 2000; i++ {
+		text += fmt.Sprintf("lorem ipsum %s dolor sit amet ", words[(i*7)%n])
+	}
+	return words, text
+}
+
+func BenchmarkWords(b *testing.B) {
+	words, text := blocklist(300)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Words(text, words, Options{Literal: true})
+	}
+}
+
+func BenchmarkWord_repeated(b *testing.B) {
+	words, text := blocklist(300)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s := text
+		for _, w := range words {
+			s, _ = WordWithOptions(s, w, Options{Literal: true})
+		}
+	}
+}
+
+func Test_WordWithOptions_haveUnicodeText_WordsAreRemovedAtUnicodeBoundaries(t *testing.T) {
+	tests := []struct {
+		word string
+		opts Options
+		In   string
+		Want string
+	}{
+		{ // German: umlauts are letters, not boundaries
+			word: `ber`,
+			opts: Options{Unicode: true},
+			In:   `über ber Überbau`,
+			Want: `über  Überbau`,
+		},
+		{ // German: simple folding of ß
+			word: `straße`,
+			opts: Options{Unicode: true, IgnoreCase: true},
+			In:   `Straße, STRAẞE und Straßenbahn`,
+			Want: `,  und Straßenbahn`,
+		},
+		{ // French: words starting with accented letters
+			word: `été`,
+			opts: Options{Unicode: true, IgnoreCase: true},
+			In:   `Cet été, l'Été et les étés`,
+			Want: `Cet , l' et les étés`,
+		},
+		{ // French: regular expressions
+			word: `na[iï]ve`,
+			opts: Options{Unicode: true},
+			In:   `naïve, naive et naïveté`,
+			Want: `,  et naïveté`,
+		},
+		{ // Russian
+			word: `мир`,
+			opts: Options{Unicode: true, IgnoreCase: true},
+			In:   `Мир миру мир!`,
+			Want: ` миру !`,
+		},
+		{ // Russian: ASCII boundaries never match Cyrillic words
+			word: `мир`,
+			In:   `Мир миру мир!`,
+			Want: `Мир миру мир!`,
+		},
+		{ // ASCII case folding
+			word: `very`,
+			opts: Options{IgnoreCase: true},
+			In:   `Very good, VERY good, everyone`,
+			Want: ` good,  good, everyone`,
+		},
+	}
+
+	for _, test := range tests {
+		got, err := WordWithOptions(test.In, test.word, test.opts)
+		if err != nil || !reflect.DeepEqual(test.Want, got) {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d:\n\ncall WordWithOptions(%#v, %#v, %+v)\n\texp: %#v\n\n\tgot: %#v, %v\n\n",
+				filepath.Base(file), line, test.In, test.word, test.opts, test.Want, got, err)
+			t.FailNow()
+		}
+	}
+	testutils.Cleanup()
+
+}
+
+func Test_Words_haveUnicodeOptions_WordsAreRemoved(t *testing.T) {
+	tests := []struct {
+		words []string
+		opts  Options
+		In    string
+		Want  string
+	}{
+		{
+			words: []string{`ÉTÉ`, `hiver`},
+			opts:  Options{Unicode: true, IgnoreCase: true},
+			In:    `été, Hiver, étés`,
+			Want:  `, , étés`,
+		},
+		{
+			words: []string{`der`, `d[ie]e`},
+			opts:  Options{Unicode: true, IgnoreCase: true},
+			In:    `Der Hund, die Katze, dieß, ändere`,
+			Want:  ` Hund,  Katze, dieß, ändere`,
+		},
+	}
+
+	for _, test := range tests {
+		got, err := Words(test.In, test.words, test.opts)
+		if err != nil || !reflect.DeepEqual(test.Want, got) {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d:\n\ncall Words(%#v, %#v, %+v)\n\texp: %#v\n\n\tgot: %#v, %v\n\n",
+				filepath.Base(file), line, test.In, test.words, test.opts, test.Want, got, err)
+			t.FailNow()
+		}
+	}
+	testutils.Cleanup()
+
+}
+
+func Test_WordWithOptions_haveTidy_GapsAreCleanedUp(t *testing.T) {
+	tests := testutils.ConversionTests{
+		{ // doubled space
+			In:   `a very good day`,
+			Want: `a good day`,
+		},
+		{ // space before punctuation
+			In:   `so very, very good! really very.`,
+			Want: `so, good! really.`,
+		},
+		{ // start and end of line
+			In:   "very good\n\tso very \nvery very",
+			Want: "good\n\tso",
+		},
+		{ // lines that become blank
+			In:   "first\n  very  \r\nlast\nvery",
+			Want: "first\nlast",
+		},
+		{ // adjacent deletions
+			In:   `a very very good day`,
+			Want: `a good day`,
+		},
+		{ // no gap to collapse
+			In:   `(very)`,
+			Want: `()`,
+		},
+	}
+
+	for _, test := range tests {
+		got, err := WordWithOptions(test.In, "very", Options{Tidy: true})
+		if err != nil || !reflect.DeepEqual(test.Want, got) {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d:\n\ncall WordWithOptions(%#v)\n\texp: %#v\n\n\tgot: %#v, %v\n\n",
+				filepath.Base(file), line, test.In, test.Want, got, err)
+			t.FailNow()
+		}
+	}
+	testutils.Cleanup()
+
+}
+
+func Test_RegExpWithOptions_haveTidy_GapsAreCleanedUp(t *testing.T) {
+	tests := testutils.ConversionTests{
+		{
+			In:   "Read more at example.com !\nsee [1] and [2] , ok\n[3]\nend",
+			Want: "Read more at example.com !\nsee and, ok\nend",
+		},
+	}
+
+	for _, test := range tests {
+		got, err := RegExpWithOptions(test.In, `\[\d+\]|q*`, Options{Tidy: true})
+		if err != nil || !reflect.DeepEqual(test.Want, got) {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d:\n\ncall RegExpWithOptions(%#v)\n\texp: %#v\n\n\tgot: %#v, %v\n\n",
+				filepath.Base(file), line, test.In, test.Want, got, err)
+			t.FailNow()
+		}
+	}
+	testutils.Cleanup()
+
+}